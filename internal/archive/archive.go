@@ -0,0 +1,387 @@
+package archive
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	readability "github.com/go-shiori/go-readability"
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/config"
+)
+
+const (
+	htmlExt = ".html"
+	// StdMaxPageSize is the size limit of a fetched page or document.
+	StdMaxPageSize = 50 << 20
+)
+
+var (
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	ErrPageTooLarge     = errors.New("page exceeds size limit")
+)
+
+// knownExt pins the extension of common media types since
+// mime.ExtensionsByType depends on the system MIME database.
+var knownExt = map[string]string{
+	"text/plain":       ".txt",
+	"text/markdown":    ".md",
+	"application/json": ".json",
+	"image/png":        ".png",
+	"image/jpeg":       ".jpg",
+	"image/gif":        ".gif",
+	"image/webp":       ".webp",
+	"image/svg+xml":    ".svg",
+}
+
+// Indexer keeps the text of the latest snapshot of every bookmark searchable.
+type Indexer interface {
+	Add(id uuid.UUID, path string, text string) error
+}
+
+// Archiver fetches pages and stores a copy of them
+// as a new snapshot under config.SnapshotDirPath.
+type Archiver struct {
+	client   *http.Client
+	template *template.Template
+	keep     int
+	assets   AssetMode
+	styles   bool
+	maxSize  int64
+	timeout  time.Duration
+	index    Indexer
+}
+
+func New(opts ...func(*Archiver)) *Archiver {
+	res := &Archiver{
+		client:   &http.Client{},
+		template: defaultTemplate(),
+		maxSize:  StdMaxAssetSize,
+		timeout:  config.StdArchiveTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// WithClient sets the HTTP client used to fetch pages. Archiving is bound
+// by its own timeout, see WithTimeout, so the client should have none.
+func WithClient(client *http.Client) func(*Archiver) {
+	return func(a *Archiver) {
+		if client != nil {
+			a.client = client
+		}
+	}
+}
+
+// WithTemplate sets the template wrapping the readable content of a page.
+// The template is executed with a Page.
+func WithTemplate(tmpl *template.Template) func(*Archiver) {
+	return func(a *Archiver) {
+		if tmpl != nil {
+			a.template = tmpl
+		}
+	}
+}
+
+// WithKeep limits the number of snapshots kept per bookmark, removing the
+// oldest ones after every new archive. Zero or less keeps all of them.
+func WithKeep(keep int) func(*Archiver) {
+	return func(a *Archiver) {
+		a.keep = keep
+	}
+}
+
+// WithAssets makes archived pages self-contained by downloading
+// their images according to mode.
+func WithAssets(mode AssetMode) func(*Archiver) {
+	return func(a *Archiver) {
+		a.assets = mode
+	}
+}
+
+// WithStyles also bundles the stylesheets of archived pages together with
+// the fonts and images they reference. Has no effect unless assets are bundled.
+func WithStyles(styles bool) func(*Archiver) {
+	return func(a *Archiver) {
+		a.styles = styles
+	}
+}
+
+// WithMaxAssetSize limits the size of a single bundled asset.
+// Larger assets keep referencing the remote URL.
+func WithMaxAssetSize(size int64) func(*Archiver) {
+	return func(a *Archiver) {
+		if size > 0 {
+			a.maxSize = size
+		}
+	}
+}
+
+// WithTimeout limits how long fetching a page, with its assets, may take.
+func WithTimeout(timeout time.Duration) func(*Archiver) {
+	return func(a *Archiver) {
+		if timeout > 0 {
+			a.timeout = timeout
+		}
+	}
+}
+
+// WithIndex adds the text of every new snapshot to index.
+func WithIndex(index Indexer) func(*Archiver) {
+	return func(a *Archiver) {
+		a.index = index
+	}
+}
+
+// Archive fetches rawURL and stores it as a new snapshot of the bookmark with
+// the given id. HTML pages are simplified to their readable content while any
+// other content type e.g. PDF or images is stored as is with a matching file
+// extension. Returns the title of the document, if one could be found.
+//
+// The snapshot is written to a temporary file first so a failed
+// fetch never leaves a partial archive behind.
+func (a *Archiver) Archive(ctx context.Context, id uuid.UUID, rawURL string) (title string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		err = errors.Join(err, res.Body.Close())
+	}()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("%s: %w", res.Status, ErrUnexpectedStatus)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, StdMaxPageSize+1))
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) > StdMaxPageSize {
+		return "", fmt.Errorf("%s: %w", rawURL, ErrPageTooLarge)
+	}
+
+	var content []byte
+	var ext string
+
+	now := time.Now().UTC()
+	stamp := now.Format(snapshotLayout)
+	mediaType := contentType(res.Header.Get("Content-Type"), data)
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		article, err := readability.FromReader(bytes.NewReader(data), res.Request.URL)
+		if err != nil {
+			return "", err
+		}
+
+		body, err := a.bundle(ctx, id, stamp+htmlExt, article.Content, data, res.Request.URL)
+		if err != nil {
+			return "", err
+		}
+
+		content, err = a.render(meta{
+			Title:   cmp.Or(article.Title, rawURL),
+			URL:     rawURL,
+			Byline:  article.Byline,
+			Fetched: now,
+		}, body)
+		if err != nil {
+			return "", err
+		}
+
+		ext, title = htmlExt, article.Title
+	case "application/pdf":
+		content, ext, title = data, ".pdf", pdfTitle(data)
+	default:
+		content, ext = data, extension(mediaType, res.Request.URL.Path)
+	}
+
+	err = a.store(id, stamp+ext, content)
+	if err != nil {
+		_ = os.RemoveAll(filepath.Join(config.SnapshotDirPath(id), assetsDir(stamp+ext)))
+		return "", err
+	}
+
+	return title, a.indexSnapshot(id, Snapshot{Path: filepath.Join(config.SnapshotDirPath(id), stamp+ext), Time: now})
+}
+
+func (a *Archiver) indexSnapshot(id uuid.UUID, s Snapshot) error {
+	if a.index == nil || (!s.HTML() && !s.Text()) {
+		return nil
+	}
+
+	text, err := s.ReadText()
+	if err != nil {
+		return err
+	}
+
+	return a.index.Add(id, s.Path, text)
+}
+
+// Render wraps the content of an existing HTML snapshot with the current
+// template in place. Snapshots archived before content was marked fall
+// back to title and rawURL for the page metadata.
+func (a *Archiver) Render(s Snapshot, title string, rawURL string) (err error) {
+	page, err := os.ReadFile(s.Path)
+	if err != nil {
+		return err
+	}
+
+	m, content, ok := extract(page)
+	if !ok {
+		m = meta{Title: title, URL: rawURL, Fetched: s.Time}
+		content, err = legacyContent(page)
+		if err != nil {
+			return err
+		}
+	}
+
+	out, err := a.render(m, content)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".archive-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = tmp.Write(out)
+	err = errors.Join(err, tmp.Close())
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+func (a *Archiver) render(m meta, content string) ([]byte, error) {
+	page, err := newPage(m, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = a.template.Execute(&buf, page)
+	return buf.Bytes(), err
+}
+
+// bundle makes the readable content of page self-contained
+// as configured and returns the content unchanged otherwise.
+func (a *Archiver) bundle(ctx context.Context, id uuid.UUID, name string, content string, page []byte, base *url.URL) (string, error) {
+	if a.assets == NoAssets {
+		return content, nil
+	}
+
+	var styles []*url.URL
+	if a.styles {
+		styles = stylesheets(page, base)
+	}
+
+	b := &bundler{
+		ctx:     ctx,
+		client:  a.client,
+		mode:    a.assets,
+		maxSize: a.maxSize,
+		dir:     filepath.Join(config.SnapshotDirPath(id), assetsDir(name)),
+		rel:     assetsDir(name),
+		done:    map[string]string{},
+	}
+
+	return b.bundle(content, styles)
+}
+
+// store writes content as the snapshot name and prunes the oldest ones.
+func (a *Archiver) store(id uuid.UUID, name string, content []byte) (err error) {
+	dir := config.SnapshotDirPath(id)
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".archive-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = tmp.Write(content)
+	err = errors.Join(err, tmp.Close())
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), filepath.Join(dir, name))
+	if err != nil || a.keep <= 0 {
+		return err
+	}
+
+	return prune(id, a.keep)
+}
+
+// contentType returns the media type from the Content-Type header
+// or sniffs it from data if the header is missing or invalid.
+func contentType(header string, data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+
+	return mediaType
+}
+
+// extension picks the file extension for mediaType, preferring
+// the one of the URL path when it matches the media type.
+func extension(mediaType string, urlPath string) string {
+	if ext, ok := knownExt[mediaType]; ok {
+		return ext
+	}
+
+	exts, _ := mime.ExtensionsByType(mediaType)
+	if pathExt := path.Ext(urlPath); slices.Contains(exts, pathExt) {
+		return pathExt
+	}
+
+	if len(exts) > 0 {
+		return exts[0]
+	}
+
+	return ".bin"
+}
@@ -0,0 +1,295 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/config"
+)
+
+const page = `<html><head><title>Test</title></head><body><article>
+<h1>Test</h1>
+<p>The quick brown fox jumps over the lazy dog, again and again, until the dog finally gets up.</p>
+<p>Meanwhile the fox keeps jumping, since there is nothing better to do on a sunny afternoon.</p>
+</article></body></html>`
+
+// setup points the archive directory to a temporary one. Tests using
+// it cannot run in parallel since the xdg paths are global.
+func setup(t *testing.T) {
+	t.Helper()
+
+	dataHome := xdg.DataHome
+	xdg.DataHome = t.TempDir()
+	t.Cleanup(func() {
+		xdg.DataHome = dataHome
+	})
+
+	err := os.MkdirAll(config.ArchiveDirPath(), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	setup(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	id := uuid.New()
+	_, err := New().Archive(context.Background(), id, srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	latest, ok := Latest(id)
+	if !ok {
+		t.Fatal("missing archive")
+	}
+
+	content, err := os.ReadFile(latest.Path)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if !strings.Contains(string(content), "quick brown fox") {
+		t.Errorf("archive does not contain page content; got %q", content)
+	}
+}
+
+func TestArchiveKeep(t *testing.T) {
+	setup(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	id := uuid.New()
+	err := os.WriteFile(config.ArchiveFilePath(id), []byte(page), config.StdFileMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archiver := New(WithKeep(2))
+	for range 3 {
+		_, err := archiver.Archive(context.Background(), id, srv.URL)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+	}
+
+	snapshots, err := Snapshots(id)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if len(snapshots) != 2 {
+		t.Fatalf("unexpected number of snapshots; want 2, got %d", len(snapshots))
+	}
+
+	if !snapshots[0].Time.After(snapshots[1].Time) {
+		t.Errorf("snapshots not sorted newest first; got %v", snapshots)
+	}
+
+	if _, err := os.Stat(config.ArchiveFilePath(id)); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected legacy archive to be pruned")
+	}
+}
+
+func TestArchiveUnexpectedStatus(t *testing.T) {
+	setup(t)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	id := uuid.New()
+	_, err := New().Archive(context.Background(), id, srv.URL)
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("unexpected error; want %q, got %q", ErrUnexpectedStatus, err)
+	}
+
+	if _, ok := Latest(id); ok {
+		t.Error("unexpected archive after failed fetch")
+	}
+
+	dd, _ := os.ReadDir(config.SnapshotDirPath(id))
+	if len(dd) != 0 {
+		t.Errorf("unexpected files left behind; got %d", len(dd))
+	}
+}
+
+func TestArchiveTooLarge(t *testing.T) {
+	setup(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(make([]byte, StdMaxPageSize+1))
+	}))
+	defer srv.Close()
+
+	id := uuid.New()
+	_, err := New().Archive(context.Background(), id, srv.URL)
+	if !errors.Is(err, ErrPageTooLarge) {
+		t.Errorf("unexpected error; want %q, got %q", ErrPageTooLarge, err)
+	}
+
+	if _, ok := Latest(id); ok {
+		t.Error("unexpected archive after failed fetch")
+	}
+}
+
+func TestArchiveTimeout(t *testing.T) {
+	setup(t)
+
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	_, err := New(WithTimeout(10*time.Millisecond)).Archive(context.Background(), uuid.New(), srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error; want %q, got %q", context.DeadlineExceeded, err)
+	}
+}
+
+func writeSnapshot(t *testing.T, dir string, name string, content string) Snapshot {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(content), config.StdFileMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Snapshot{Path: path}
+}
+
+func TestArchiveContentType(t *testing.T) {
+	setup(t)
+
+	pdf := "%PDF-1.4\n1 0 obj\n<< /Title (A Paper) >>\nendobj\n%%EOF"
+	tsc := map[string]struct {
+		path        string
+		contentType string
+		body        string
+		ext         string
+		title       string
+	}{
+		"html": {
+			path:        "/article",
+			contentType: "text/html; charset=utf-8",
+			body:        page,
+			ext:         ".html",
+			title:       "Test",
+		},
+		"pdf": {
+			path:        "/paper.pdf",
+			contentType: "application/pdf",
+			body:        pdf,
+			ext:         ".pdf",
+			title:       "A Paper",
+		},
+		"sniffed-pdf": {
+			path: "/download",
+			body: pdf,
+			ext:  ".pdf",
+		},
+		"text": {
+			path:        "/rfc.txt",
+			contentType: "text/plain",
+			body:        "plain text",
+			ext:         ".txt",
+		},
+		"image": {
+			path:        "/logo",
+			contentType: "image/png",
+			body:        "\x89PNG\r\n\x1a\n",
+			ext:         ".png",
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", c.contentType)
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer srv.Close()
+
+			id := uuid.New()
+			title, err := New().Archive(context.Background(), id, srv.URL+c.path)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			latest, ok := Latest(id)
+			if !ok {
+				t.Fatal("missing archive")
+			}
+
+			if filepath.Ext(latest.Path) != c.ext {
+				t.Errorf("unexpected extension; want %q, got %q", c.ext, filepath.Ext(latest.Path))
+			}
+
+			if c.title != "" && title != c.title {
+				t.Error(cmp.Diff(c.title, title))
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	setup(t)
+
+	legacy, both, other := uuid.New(), uuid.New(), uuid.New()
+	files := map[string]string{
+		config.ArchiveFilePath(legacy):                                  "12345",
+		config.ArchiveFilePath(both):                                    "12",
+		filepath.Join(config.SnapshotDirPath(both), "a.html"):           "123",
+		filepath.Join(config.SnapshotDirPath(both), "a_files", "x.png"): "1234",
+		filepath.Join(config.ArchiveDirPath(), "notes.txt"):             "ignored",
+		filepath.Join(config.ArchiveDirPath(), other.String()+".tmp"):   "ignored",
+	}
+
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), config.StdFileMode)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Entries()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got := map[uuid.UUID]int64{}
+	for _, e := range entries {
+		got[e.Id] = e.Size
+	}
+
+	want := map[uuid.UUID]int64{legacy: 5, both: 9}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,306 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/loghinalexandru/anchor/internal/config"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// StdMaxAssetSize is the default size limit of a single image or stylesheet.
+const StdMaxAssetSize = 5 << 20
+
+// cssURLRegexp matches url() references with double, single or no quotes.
+var cssURLRegexp = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]*))\s*\)`)
+
+var (
+	ErrInvalidAssetMode = errors.New("invalid asset mode")
+	ErrInvalidSize      = errors.New("invalid size")
+	ErrAssetTooLarge    = errors.New("asset exceeds size limit")
+	ErrUnexpectedType   = errors.New("unexpected asset type")
+)
+
+// AssetMode controls how images and stylesheets referenced
+// by an archived page are made available offline.
+type AssetMode int
+
+const (
+	// NoAssets keeps referencing the remote assets.
+	NoAssets AssetMode = iota
+	// InlineAssets embeds assets in the page as data URIs.
+	InlineAssets
+	// LocalAssets stores assets in a directory beside the page.
+	LocalAssets
+)
+
+var assetModeNames = []string{"none", "inline", "local"}
+
+func ParseAssetMode(s string) (AssetMode, error) {
+	idx := slices.Index(assetModeNames, strings.ToLower(strings.TrimSpace(s)))
+	if idx == -1 {
+		return NoAssets, fmt.Errorf("%q: %w", s, ErrInvalidAssetMode)
+	}
+
+	return AssetMode(idx), nil
+}
+
+func (m AssetMode) String() string {
+	return assetModeNames[m]
+}
+
+// ParseSize parses a number of bytes with an optional
+// KB, MB or GB suffix using powers of 1024 e.g. "512KB".
+func ParseSize(s string) (int64, error) {
+	num := strings.ToUpper(strings.TrimSpace(s))
+	mul := int64(1)
+
+	for i, suffix := range []string{"KB", "MB", "GB"} {
+		if trimmed, ok := strings.CutSuffix(num, suffix); ok {
+			num, mul = strings.TrimSpace(trimmed), 1<<(10*(i+1))
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSuffix(num, "B"), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q: %w", s, ErrInvalidSize)
+	}
+
+	return n * mul, nil
+}
+
+// assetsDir is the directory holding the local assets of the snapshot name.
+func assetsDir(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "_files"
+}
+
+// bundler rewrites the assets of a page according to mode. Assets that
+// fail to download or exceed maxSize keep pointing to the remote URL.
+type bundler struct {
+	ctx     context.Context
+	client  *http.Client
+	mode    AssetMode
+	maxSize int64
+	// dir is where local assets are written and
+	// rel is how the page refers to that directory.
+	dir string
+	rel string
+
+	done map[string]string
+}
+
+// bundle rewrites the images of content and, if styles are given,
+// prepends them to the content so the page renders without network.
+func (b *bundler) bundle(content string, styles []*url.URL) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var res bytes.Buffer
+	for _, u := range styles {
+		ref, ok := b.fetch(u, "text/css")
+		if !ok {
+			continue
+		}
+
+		// Data URIs work in link elements as well and keep CSS
+		// out of a <style> element where it would need escaping.
+		res.WriteString(`<link rel="stylesheet" href="` + html.EscapeString(ref) + `">`)
+	}
+
+	for _, n := range nodes {
+		b.walk(n)
+		err = html.Render(&res, n)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return res.String(), nil
+}
+
+func (b *bundler) walk(n *html.Node) {
+	if n.Type == html.ElementNode && (n.DataAtom == atom.Img || n.DataAtom == atom.Source) {
+		b.rewrite(n)
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.walk(c)
+	}
+}
+
+// rewrite points the src of an image to the bundled copy and drops
+// srcset so that browsers do not pick a remote candidate instead.
+func (b *bundler) rewrite(n *html.Node) {
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "srcset", "sizes", "loading":
+			continue
+		case "src":
+			u, err := url.Parse(a.Val)
+			if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				if ref, ok := b.fetch(u, "image/"); ok {
+					a.Val = ref
+				}
+			}
+		}
+
+		attrs = append(attrs, a)
+	}
+
+	n.Attr = attrs
+}
+
+// fetch downloads u if its media type starts with prefix and returns
+// the data URI or relative path the page should use instead.
+func (b *bundler) fetch(u *url.URL, prefix string) (string, bool) {
+	if ref, ok := b.done[u.String()]; ok {
+		return ref, ref != ""
+	}
+
+	ref, err := b.download(u, prefix)
+	if err != nil {
+		ref = ""
+	}
+
+	b.done[u.String()] = ref
+	return ref, ref != ""
+}
+
+func (b *bundler) download(u *url.URL, prefix string) (ref string, err error) {
+	req, err := http.NewRequestWithContext(b.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		err = errors.Join(err, res.Body.Close())
+	}()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("%s: %w", res.Status, ErrUnexpectedStatus)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, b.maxSize+1))
+	if err != nil {
+		return "", err
+	}
+
+	if int64(len(data)) > b.maxSize {
+		return "", fmt.Errorf("%s: %w", u, ErrAssetTooLarge)
+	}
+
+	mediaType := contentType(res.Header.Get("Content-Type"), data)
+	if !strings.HasPrefix(mediaType, prefix) {
+		return "", fmt.Errorf("%s: %w", mediaType, ErrUnexpectedType)
+	}
+
+	if mediaType == "text/css" {
+		data = b.rewriteCSS(data, res.Request.URL)
+	}
+
+	if b.mode == InlineAssets {
+		return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+	}
+
+	sum := sha1.Sum([]byte(u.String()))
+	name := hex.EncodeToString(sum[:8]) + extension(mediaType, u.Path)
+
+	err = os.MkdirAll(b.dir, os.ModePerm)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(b.dir, name), data, config.StdFileMode)
+	if err != nil {
+		return "", err
+	}
+
+	return b.rel + "/" + name, nil
+}
+
+// rewriteCSS points the url() references of a stylesheet e.g. fonts and
+// background images to bundled copies. Local copies are referenced relative
+// to the stylesheet, which is stored in the same directory.
+func (b *bundler) rewriteCSS(data []byte, base *url.URL) []byte {
+	return cssURLRegexp.ReplaceAllFunc(data, func(match []byte) []byte {
+		sub := cssURLRegexp.FindSubmatch(match)
+		raw := string(bytes.Join(sub[1:], nil))
+
+		u, err := base.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" {
+			return match
+		}
+
+		ref, ok := b.fetch(u, "")
+		if !ok {
+			return match
+		}
+
+		return []byte(`url("` + strings.TrimPrefix(ref, b.rel+"/") + `")`)
+	})
+}
+
+// stylesheets returns the absolute URLs of the stylesheets linked by the page.
+func stylesheets(page []byte, base *url.URL) []*url.URL {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return nil
+	}
+
+	var res []*url.URL
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Link {
+			var rel, href string
+			for _, a := range n.Attr {
+				switch a.Key {
+				case "rel":
+					rel = a.Val
+				case "href":
+					href = a.Val
+				}
+			}
+
+			if slices.Contains(strings.Fields(strings.ToLower(rel)), "stylesheet") && href != "" {
+				if u, err := base.Parse(href); err == nil {
+					res = append(res, u)
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(doc)
+	return res
+}
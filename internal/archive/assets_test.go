@@ -0,0 +1,169 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/config"
+)
+
+const png = "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"
+
+func assetServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logo.png", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(png))
+	})
+	mux.HandleFunc("/huge.png", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(png + strings.Repeat("x", 1024)))
+	})
+	mux.HandleFunc("/site.css", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		_, _ = w.Write([]byte(`@font-face { src: url("fonts/sans.woff2"); } body { background: url( '/bg.png' ); }`))
+	})
+	mux.HandleFunc("/fonts/sans.woff2", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "font/woff2")
+		_, _ = w.Write([]byte("wOF2"))
+	})
+	mux.HandleFunc("/bg.png", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(png))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		host := "http://" + r.Host
+		_, _ = w.Write([]byte(`<html><head><title>Test</title><link rel="stylesheet" href="/site.css"></head><body><article>
+<h1>Test</h1>
+<p>The quick brown fox jumps over the lazy dog, again and again, until the dog finally gets up.</p>
+<img src="` + host + `/logo.png" srcset="` + host + `/logo.png 2x" alt="logo">
+<img src="` + host + `/huge.png" alt="huge">
+<p>Meanwhile the fox keeps jumping, since there is nothing better to do on a sunny afternoon.</p>
+</article></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestArchiveAssets(t *testing.T) {
+	setup(t)
+	srv := assetServer(t)
+
+	tsc := map[string]struct {
+		mode  AssetMode
+		want  []string
+		local bool
+	}{
+		"none": {
+			mode: NoAssets,
+			want: []string{srv.URL + "/logo.png", "srcset"},
+		},
+		"inline": {
+			mode: InlineAssets,
+			want: []string{"data:image/png;base64,", "data:text/css;base64,", srv.URL + "/huge.png"},
+		},
+		"local": {
+			mode:  LocalAssets,
+			want:  []string{"_files/", `rel="stylesheet"`, srv.URL + "/huge.png"},
+			local: true,
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			id := uuid.New()
+			archiver := New(WithAssets(c.mode), WithStyles(true), WithMaxAssetSize(512))
+
+			_, err := archiver.Archive(context.Background(), id, srv.URL)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			latest, _ := Latest(id)
+			content, err := os.ReadFile(latest.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, w := range c.want {
+				if !strings.Contains(string(content), w) {
+					t.Errorf("archive does not contain %q; got %q", w, content)
+				}
+			}
+
+			dir := filepath.Join(config.SnapshotDirPath(id), assetsDir(filepath.Base(latest.Path)))
+			dd, _ := os.ReadDir(dir)
+			if !c.local {
+				return
+			}
+
+			if len(dd) != 4 {
+				t.Errorf("unexpected number of local assets; want 4, got %d", len(dd))
+			}
+
+			for _, d := range dd {
+				if filepath.Ext(d.Name()) != ".css" {
+					continue
+				}
+
+				css, err := os.ReadFile(filepath.Join(dir, d.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if strings.Contains(string(css), "sans.woff2") || strings.Contains(string(css), "bg.png") {
+					t.Errorf("stylesheet references remote assets; got %q", css)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]struct {
+		in   string
+		want int64
+		err  error
+	}{
+		"bytes":     {in: "512", want: 512},
+		"kilobytes": {in: "4KB", want: 4 << 10},
+		"megabytes": {in: "2 mb", want: 2 << 20},
+		"invalid":   {in: "lots", err: ErrInvalidSize},
+		"suffix":    {in: "12 tb", err: ErrInvalidSize},
+		"negative":  {in: "-1", err: ErrInvalidSize},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseSize(c.in)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("unexpected error; want %q, got %q", c.err, err)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), strconv.Quote(c.in)) {
+				t.Errorf("error does not name the input; want %q, got %q", c.in, err)
+			}
+
+			if got != c.want {
+				t.Errorf("unexpected size; want %d, got %d", c.want, got)
+			}
+		})
+	}
+}
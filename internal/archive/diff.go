@@ -0,0 +1,135 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	ErrNoText = errors.New("snapshot has no text to compare")
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is a line of text of a snapshot diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Diff compares the text of two snapshots line by line.
+func Diff(older, newer Snapshot) ([]Line, error) {
+	oldText, err := older.ReadText()
+	if err != nil {
+		return nil, err
+	}
+
+	newText, err := newer.ReadText()
+	if err != nil {
+		return nil, err
+	}
+
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	var res []Line
+	for _, d := range diffs {
+		op := Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = Insert
+		case diffmatchpatch.DiffDelete:
+			op = Delete
+		}
+
+		for _, l := range strings.Split(strings.TrimSuffix(d.Text, "\n"), "\n") {
+			res = append(res, Line{Op: op, Text: l})
+		}
+	}
+
+	return res, nil
+}
+
+// ReadText returns the text of an HTML or text snapshot. Other
+// snapshots e.g. PDFs or images return ErrNoText.
+func (s Snapshot) ReadText() (string, error) {
+	if !s.HTML() && !s.Text() {
+		return "", fmt.Errorf("%s: %w", filepath.Base(s.Path), ErrNoText)
+	}
+
+	raw, err := os.ReadFile(s.Path)
+	if err != nil || s.Text() {
+		return string(raw), err
+	}
+
+	return Text(bytes.NewReader(Content(raw)))
+}
+
+// Text extracts the visible text of the HTML document from r
+// with every block element on its own line.
+func Text(r io.Reader) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+
+	var res, curr strings.Builder
+	flush := func() {
+		line := strings.Join(strings.Fields(curr.String()), " ")
+		curr.Reset()
+
+		if line != "" {
+			res.WriteString(line + "\n")
+		}
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			curr.WriteString(n.Data)
+			return
+		}
+
+		block := false
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Template, atom.Svg:
+				return
+			case atom.P, atom.Div, atom.Li, atom.Pre, atom.Blockquote, atom.Tr, atom.Br, atom.Hr,
+				atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Ul, atom.Ol, atom.Table,
+				atom.Section, atom.Article, atom.Header, atom.Footer, atom.Dt, atom.Dd, atom.Figcaption:
+				block = true
+				flush()
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+
+		if block {
+			flush()
+		}
+	}
+
+	walk(doc)
+	flush()
+
+	return res.String(), nil
+}
@@ -0,0 +1,49 @@
+package archive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	in := `<div><h1>Title</h1><p>First <b>bold</b>
+	paragraph.</p><ul><li>one</li><li>two</li></ul><script>var x;</script></div>`
+	want := "Title\nFirst bold paragraph.\none\ntwo\n"
+
+	got, err := Text(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	older := writeSnapshot(t, dir, "older.html", "<p>same</p><p>removed</p><p>end</p>")
+	newer := writeSnapshot(t, dir, "newer.html", "<p>same</p><p>added</p><p>end</p>")
+
+	want := []Line{
+		{Op: Equal, Text: "same"},
+		{Op: Delete, Text: "removed"},
+		{Op: Insert, Text: "added"},
+		{Op: Equal, Text: "end"},
+	}
+
+	got, err := Diff(older, newer)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
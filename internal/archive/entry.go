@@ -0,0 +1,81 @@
+package archive
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/config"
+)
+
+// Entry is everything archived for a single bookmark.
+type Entry struct {
+	Id   uuid.UUID
+	Size int64
+}
+
+// Entries returns what is archived per bookmark, snapshots and legacy
+// archives combined. Files not named after a bookmark id are ignored.
+func Entries() ([]Entry, error) {
+	dd, err := os.ReadDir(config.ArchiveDirPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Entry
+	index := map[uuid.UUID]int{}
+
+	for _, d := range dd {
+		name := d.Name()
+		if !d.IsDir() {
+			name = strings.TrimSuffix(name, htmlExt)
+		}
+
+		id, err := uuid.Parse(name)
+		if err != nil {
+			continue
+		}
+
+		size, err := size(filepath.Join(config.ArchiveDirPath(), d.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := index[id]; ok {
+			res[i].Size += size
+			continue
+		}
+
+		index[id] = len(res)
+		res = append(res, Entry{Id: id, Size: size})
+	}
+
+	return res, nil
+}
+
+// size returns the size of the file at path or of all files below it.
+func size(path string) (int64, error) {
+	var res int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		res += info.Size()
+		return nil
+	})
+
+	return res, err
+}
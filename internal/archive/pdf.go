@@ -0,0 +1,96 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	pdfLiteralTitle = regexp.MustCompile(`/Title\s*\(((?:\\.|[^\\)])*)\)`)
+	pdfHexTitle     = regexp.MustCompile(`/Title\s*<([0-9A-Fa-f\s]+)>`)
+)
+
+// pdfTitle returns the title from the document information dictionary
+// of a PDF or an empty string if it is missing or stored compressed.
+func pdfTitle(data []byte) string {
+	if m := pdfLiteralTitle.FindSubmatch(data); m != nil {
+		return pdfText(unescapeLiteral(m[1]))
+	}
+
+	if m := pdfHexTitle.FindSubmatch(data); m != nil {
+		raw, err := hex.DecodeString(strings.Join(strings.Fields(string(m[1])), ""))
+		if err == nil {
+			return pdfText(raw)
+		}
+	}
+
+	return ""
+}
+
+// unescapeLiteral resolves the backslash escapes of a PDF literal string.
+func unescapeLiteral(s []byte) []byte {
+	var res bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			res.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 'n':
+			res.WriteByte('\n')
+		case 'r':
+			res.WriteByte('\r')
+		case 't':
+			res.WriteByte('\t')
+		case 'b':
+			res.WriteByte('\b')
+		case 'f':
+			res.WriteByte('\f')
+		case '\r', '\n':
+			// Line continuation.
+		default:
+			if c < '0' || c > '7' {
+				res.WriteByte(c)
+				break
+			}
+
+			// Up to three octal digits.
+			n := 0
+			for j := 0; j < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7'; j++ {
+				n = n*8 + int(s[i]-'0')
+				i++
+			}
+
+			i--
+			res.WriteByte(byte(n))
+		}
+	}
+
+	return res.Bytes()
+}
+
+// pdfText decodes a PDF text string which is either UTF-16BE
+// with a byte order mark or PDFDocEncoding, close enough to Latin-1.
+func pdfText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		b = b[2:]
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		}
+
+		return strings.TrimSpace(string(utf16.Decode(units)))
+	}
+
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+
+	return strings.TrimSpace(string(runes))
+}
@@ -0,0 +1,48 @@
+package archive
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPDFTitle(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]struct {
+		in   string
+		want string
+	}{
+		"literal": {
+			in:   "1 0 obj\n<< /Title (Attention Is All You Need) /Author (Vaswani) >>\nendobj",
+			want: "Attention Is All You Need",
+		},
+		"escaped": {
+			in:   `<</Title (Go \(the language\) \\ spec\101)>>`,
+			want: `Go (the language) \ specA`,
+		},
+		"hex-utf16": {
+			in:   "<</Title <FEFF00470020263A>>>",
+			want: "G ☺",
+		},
+		"latin1": {
+			in:   "<</Title (Caf\\351)>>",
+			want: "Café",
+		},
+		"missing": {
+			in:   "%PDF-1.7\n<</Type /Catalog>>",
+			want: "",
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			got := pdfTitle([]byte(c.in))
+			if got != c.want {
+				t.Error(cmp.Diff(c.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/config"
+)
+
+// snapshotLayout names snapshot files so that they sort chronologically.
+const snapshotLayout = "20060102T150405.000000Z"
+
+// Snapshot is a single archive of a bookmark taken at Time.
+type Snapshot struct {
+	Path string
+	Time time.Time
+}
+
+// HTML reports whether the snapshot is a readable page
+// as opposed to a raw file e.g. a PDF or an image.
+func (s Snapshot) HTML() bool {
+	return filepath.Ext(s.Path) == htmlExt
+}
+
+// Text reports whether the snapshot is plain text.
+func (s Snapshot) Text() bool {
+	ext := filepath.Ext(s.Path)
+	return ext == ".txt" || ext == ".md"
+}
+
+// Snapshots returns every archive of the bookmark with the given id, newest
+// first. The legacy single archive, if present, is the oldest snapshot.
+func Snapshots(id uuid.UUID) ([]Snapshot, error) {
+	var res []Snapshot
+
+	dd, err := os.ReadDir(config.SnapshotDirPath(id))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, d := range dd {
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+
+		name := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+		ts, err := time.Parse(snapshotLayout, name)
+		if err != nil {
+			continue
+		}
+
+		res = append(res, Snapshot{
+			Path: filepath.Join(config.SnapshotDirPath(id), d.Name()),
+			Time: ts,
+		})
+	}
+
+	slices.SortFunc(res, func(a, b Snapshot) int {
+		return b.Time.Compare(a.Time)
+	})
+
+	info, err := os.Stat(config.ArchiveFilePath(id))
+	if err == nil {
+		res = append(res, Snapshot{
+			Path: config.ArchiveFilePath(id),
+			Time: info.ModTime(),
+		})
+	}
+
+	return res, nil
+}
+
+// Latest returns the newest archive of the bookmark with
+// the given id and false if the bookmark has none.
+func Latest(id uuid.UUID) (Snapshot, bool) {
+	snapshots, err := Snapshots(id)
+	if err != nil || len(snapshots) == 0 {
+		return Snapshot{}, false
+	}
+
+	return snapshots[0], true
+}
+
+// Remove deletes every archive of the bookmark with the given id.
+// Has no effect if the bookmark has none.
+func Remove(id uuid.UUID) error {
+	err := os.Remove(config.ArchiveFilePath(id))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return os.RemoveAll(config.SnapshotDirPath(id))
+}
+
+// prune removes all but the keep newest archives of the bookmark.
+func prune(id uuid.UUID, keep int) error {
+	snapshots, err := Snapshots(id)
+	if err != nil || len(snapshots) <= keep {
+		return err
+	}
+
+	for _, s := range snapshots[keep:] {
+		err = errors.Join(err, os.Remove(s.Path), os.RemoveAll(assetsDir(s.Path)))
+	}
+
+	return err
+}
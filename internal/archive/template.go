@@ -0,0 +1,167 @@
+package archive
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	contentStart = "<!--anchor:content "
+	contentEnd   = "<!--/anchor:content-->"
+)
+
+//go:embed template.html
+var stdTemplate string
+
+// Page is the data available to archive templates.
+type Page struct {
+	Title   string
+	URL     string
+	Byline  string
+	Fetched time.Time
+	// Content is the readable content of the page wrapped in markers
+	// that allow archives to be rendered again with another template.
+	Content template.HTML
+}
+
+// meta is stored in the content start marker.
+type meta struct {
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Byline  string    `json:"byline,omitempty"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// LoadTemplate parses the archive template at path and
+// returns nil without an error if the file does not exist.
+func LoadTemplate(path string) (*template.Template, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("archive").Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return tmpl, nil
+}
+
+func defaultTemplate() *template.Template {
+	return template.Must(template.New("archive").Parse(stdTemplate))
+}
+
+// newPage builds the template data for content marking it with the metadata.
+func newPage(m meta, content string) (Page, error) {
+	// Encoding escapes "<" and ">" so the metadata cannot close the comment.
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return Page{}, err
+	}
+
+	return Page{
+		Title:   m.Title,
+		URL:     m.URL,
+		Byline:  m.Byline,
+		Fetched: m.Fetched,
+		Content: template.HTML(contentStart + string(raw) + "-->" + content + contentEnd),
+	}, nil
+}
+
+// Content returns the readable content of an archived page without the
+// surrounding template or the whole page if it has no content markers.
+func Content(page []byte) []byte {
+	_, content, ok := extract(page)
+	if !ok {
+		return page
+	}
+
+	return []byte(content)
+}
+
+// extract splits an archived page into its metadata and content.
+func extract(page []byte) (meta, string, bool) {
+	s := string(page)
+
+	start := strings.Index(s, contentStart)
+	if start == -1 {
+		return meta{}, "", false
+	}
+
+	s = s[start+len(contentStart):]
+	raw, rest, ok := strings.Cut(s, "-->")
+	if !ok {
+		return meta{}, "", false
+	}
+
+	content, _, ok := strings.Cut(rest, contentEnd)
+	if !ok {
+		return meta{}, "", false
+	}
+
+	var m meta
+	if json.Unmarshal([]byte(raw), &m) != nil {
+		return meta{}, "", false
+	}
+
+	return m, content, true
+}
+
+// legacyContent returns the content of pages archived with the
+// original template which wrapped it in a single styled <div>.
+func legacyContent(page []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return "", err
+	}
+
+	body := find(doc, atom.Body)
+	if body == nil {
+		return "", nil
+	}
+
+	root := body
+	if c := body.FirstChild; c != nil && c == body.LastChild && c.DataAtom == atom.Div {
+		root = c
+	}
+
+	var buf bytes.Buffer
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		err = html.Render(&buf, c)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return buf.String(), nil
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if res := find(c, a); res != nil {
+			return res
+		}
+	}
+
+	return nil
+}
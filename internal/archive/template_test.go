@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContent(t *testing.T) {
+	t.Parallel()
+
+	m := meta{
+		Title:   "Comments --> and <tags>",
+		URL:     "https://go.dev/ref/spec",
+		Fetched: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	page, err := newPage(m, "<p>content</p>")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	wrapped := "<html><body><header>" + m.Title + "</header>" + string(page.Content) + "</body></html>"
+
+	got, content, ok := extract([]byte(wrapped))
+	if !ok {
+		t.Fatal("missing content markers")
+	}
+
+	if !cmp.Equal(m, got) {
+		t.Error(cmp.Diff(m, got))
+	}
+
+	if content != "<p>content</p>" {
+		t.Error(cmp.Diff("<p>content</p>", content))
+	}
+
+	if string(Content([]byte("<p>unmarked</p>"))) != "<p>unmarked</p>" {
+		t.Error("expected unmarked page to be returned as is")
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	legacy := writeSnapshot(t, dir, "legacy.html", `<div style="max-width: 40em;"><h1>Old</h1><p>Legacy content.</p></div>`)
+	legacy.Time = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	tmplPath := filepath.Join(dir, "archive.html")
+	err := os.WriteFile(tmplPath, []byte(`<title>{{.Title}}</title><a href="{{.URL}}">{{.Fetched.Format "2006"}}</a>{{.Content}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := LoadTemplate(tmplPath)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	archiver := New(WithTemplate(tmpl))
+	for range 2 {
+		// Rendering twice must keep the metadata and content intact.
+		err = archiver.Render(legacy, "Title", "https://example.com")
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+	}
+
+	got, err := os.ReadFile(legacy.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"<title>Title</title>", `href="https://example.com"`, ">2024</a>", "<h1>Old</h1><p>Legacy content.</p>"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("rendered archive does not contain %q; got %q", want, got)
+		}
+	}
+
+	if strings.Count(string(got), contentStart) != 1 {
+		t.Errorf("expected a single content marker; got %q", got)
+	}
+}
+
+func TestLoadTemplateMissing(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := LoadTemplate(filepath.Join(t.TempDir(), "missing.html"))
+	if tmpl != nil || err != nil {
+		t.Errorf("expected no template and no error; got %v, %q", tmpl, err)
+	}
+}
@@ -3,11 +3,9 @@ package command
 import (
 	"context"
 	"errors"
-	"fmt"
-	"html/template"
 	"os"
 
-	readability "github.com/go-shiori/go-readability"
+	"github.com/loghinalexandru/anchor/internal/archive"
 	"github.com/loghinalexandru/anchor/internal/command/util/label"
 	"github.com/loghinalexandru/anchor/internal/command/util/parser"
 	"github.com/loghinalexandru/anchor/internal/config"
@@ -31,8 +29,9 @@ const (
 
   You can also provide a comment via the flag -c for the bookmark instead of the default URL that is specified. 
 
-  If you wish to store locally the target page you can specify the -e flag with a CSS style expression
-  and it will fetch and store a simplified version of the page locally.
+  If you wish to store locally the target page you can specify the -a flag and it will fetch and store
+  a simplified version of the page locally. Other documents e.g. PDFs or images are stored as they are
+  and, if no title was found, the title of the document is used for the bookmark when available.
 
 EXAMPLES
   # Append to default label
@@ -88,30 +87,27 @@ func (add *addCmd) handle(ctx appContext, args []string) error {
 		return err
 	}
 
-	err = b.Write(file)
-	err = errors.Join(err, file.Close())
-	if err != nil {
-		return err
-	}
-
+	// Archive before writing the bookmark since documents e.g. PDFs
+	// have no <title> tag and the archive might know a better title.
+	var archiveErr error
 	if add.archive {
-		filePath := config.ArchiveFilePath(b.Id())
-		fh, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY, config.StdFileMode)
+		err = b.Check(file)
 		if err != nil {
-			return fmt.Errorf("could not open file, make sure you run the `init` command first")
+			return errors.Join(err, file.Close())
 		}
 
-		content, err := readability.FromURL(b.URL(), config.StdHttpTimeout)
-		if err != nil {
-			return err
+		var title string
+		title, archiveErr = ctx.archiver.Archive(ctx, b.Id(), b.URL())
+		if title != "" && add.title == "" && b.Title() == b.URL() {
+			b.Update(title)
 		}
+	}
 
-		err = ctx.template.Execute(fh, template.HTML(content.Content))
-		errors.Join(err, fh.Close())
-		if err != nil {
-			return err
-		}
+	err = b.Write(file)
+	if err != nil && add.archive {
+		_ = archive.Remove(b.Id())
+		_ = ctx.index.Remove(b.Id())
 	}
 
-	return nil
+	return errors.Join(err, file.Close(), archiveErr)
 }
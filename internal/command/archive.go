@@ -0,0 +1,80 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/loghinalexandru/anchor/internal/command/util/label"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	archiveName      = "archive"
+	archiveUsage     = "anchor archive <SUBCOMMAND>"
+	archiveShortHelp = "manage local copies of bookmarked pages"
+	archiveLongHelp  = `  Stores simplified copies of bookmarked pages so they can be read offline and keeps
+  them tidy.
+
+SUBCOMMANDS
+  fetch   store a local copy of bookmarks without one
+  gc      remove archives of bookmarks that no longer exist
+  stats   show disk usage of archives
+  render  wrap existing archives with the current template
+`
+)
+
+type archiveCmd struct{}
+
+func (*archiveCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("archive").SetParent(parent)
+
+	return &ff.Command{
+		Name:      archiveName,
+		Usage:     archiveUsage,
+		ShortHelp: archiveShortHelp,
+		LongHelp:  archiveLongHelp,
+		Flags:     flags,
+		Subcommands: []*ff.Command{
+			(&archiveFetchCmd{}).manifest(flags),
+			(&archiveGcCmd{}).manifest(flags),
+			(&archiveStatsCmd{}).manifest(flags),
+			(&archiveRenderCmd{}).manifest(flags),
+		},
+	}
+}
+
+// readLabels returns the names of the labels equal to or nested under labels
+// and the bookmarks of each of them.
+func readLabels(labels []string) ([]string, map[string][]*model.Bookmark, error) {
+	names, err := label.List(config.DataDirPath(), labels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := make(map[string][]*model.Bookmark, len(names))
+	for _, name := range names {
+		res[name], err = label.Bookmarks(config.DataDirPath(), name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return names, res, nil
+}
+
+// formatSize formats n bytes using the largest fitting binary unit.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
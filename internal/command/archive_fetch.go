@@ -0,0 +1,149 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	archiveFetchName      = "fetch"
+	archiveFetchUsage     = "anchor archive fetch [FLAGS] [LABEL...]"
+	archiveFetchShortHelp = "store a local copy of bookmarks without one"
+	archiveFetchLongHelp  = `  Walks all the bookmarks under [LABEL] and its sub-labels and stores a simplified version
+  of every page that has no archive yet. If no label is provided, all labels are walked.
+
+  Bookmarks that already have an archive are skipped unless the --refresh flag is set, in
+  which case a new snapshot is taken. Combined with --older-than only bookmarks with the
+  latest snapshot older than the given duration are fetched again.
+
+  Pages are fetched concurrently, at most -j at a time. Failures are reported per URL
+  and do not stop the remaining bookmarks from being archived.
+
+EXAMPLES
+  # Archive every bookmark that has no archive yet
+  anchor archive fetch
+
+  # Refresh archives under label "programming" older than 30 days
+  anchor archive fetch --refresh --older-than 720h programming
+`
+)
+
+const (
+	stdArchiveJobs = 4
+)
+
+var (
+	ErrArchiveFailed = errors.New("failed to archive bookmarks")
+	ErrInvalidJobs   = errors.New("jobs must be greater than zero")
+)
+
+type archiveFetchCmd struct {
+	refresh   bool
+	olderThan time.Duration
+	jobs      int
+}
+
+func (arc *archiveFetchCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("fetch").SetParent(parent)
+	flags.BoolVar(&arc.refresh, 0, "refresh", "fetch again bookmarks that already have an archive")
+	flags.DurationVar(&arc.olderThan, 0, "older-than", 0, "with --refresh, only fetch archives older than this")
+	flags.IntVar(&arc.jobs, 'j', "jobs", stdArchiveJobs, "number of pages fetched concurrently")
+
+	return &ff.Command{
+		Name:      archiveFetchName,
+		Usage:     archiveFetchUsage,
+		ShortHelp: archiveFetchShortHelp,
+		LongHelp:  archiveFetchLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return arc.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (arc *archiveFetchCmd) handle(ctx appContext, args []string) error {
+	if arc.jobs <= 0 {
+		return ErrInvalidJobs
+	}
+
+	names, bookmarks, err := readLabels(args)
+	if err != nil {
+		return err
+	}
+
+	var pending []*model.Bookmark
+	for _, name := range names {
+		for _, bk := range bookmarks[name] {
+			if arc.stale(bk) {
+				pending = append(pending, bk)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed int
+	sem := make(chan struct{}, arc.jobs)
+
+	for _, bk := range pending {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			_, err := ctx.archiver.Archive(ctx, bk.Id(), bk.URL())
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "Failed %s: %v\n", bk.URL(), err)
+				return
+			}
+
+			fmt.Printf("Archived %s\n", bk.URL())
+		}()
+	}
+
+	wg.Wait()
+	fmt.Printf("Archived %d of %d bookmarks\n", len(pending)-failed, len(pending))
+
+	if failed > 0 {
+		return fmt.Errorf("%d: %w", failed, ErrArchiveFailed)
+	}
+
+	return nil
+}
+
+// stale reports whether bk has no archive or, when refreshing,
+// whether its archive is older than the configured age.
+func (arc *archiveFetchCmd) stale(bk *model.Bookmark) bool {
+	latest, ok := archive.Latest(bk.Id())
+	if !ok {
+		return true
+	}
+
+	if !arc.refresh {
+		return false
+	}
+
+	return time.Since(latest.Time) > arc.olderThan
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	archiveGcName      = "gc"
+	archiveGcUsage     = "anchor archive gc [FLAGS]"
+	archiveGcShortHelp = "remove archives of bookmarks that no longer exist"
+	archiveGcLongHelp  = `  Finds archives whose bookmark id is not present in any label, e.g. after editing
+  the label files by hand, and removes them together with all their snapshots.
+
+  Use --dry-run to only list what would be removed.`
+)
+
+type archiveGcCmd struct {
+	dryRun bool
+}
+
+func (gc *archiveGcCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("gc").SetParent(parent)
+	flags.BoolVar(&gc.dryRun, 0, "dry-run", "list orphaned archives without removing them")
+
+	return &ff.Command{
+		Name:      archiveGcName,
+		Usage:     archiveGcUsage,
+		ShortHelp: archiveGcShortHelp,
+		LongHelp:  archiveGcLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return gc.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (gc *archiveGcCmd) handle(ctx appContext, _ []string) error {
+	// Any label that fails to parse aborts the collection since
+	// its bookmarks would otherwise be considered orphans.
+	_, bookmarks, err := readLabels(nil)
+	if err != nil {
+		return err
+	}
+
+	known := map[uuid.UUID]bool{}
+	for _, bks := range bookmarks {
+		for _, bk := range bks {
+			known[bk.Id()] = true
+		}
+	}
+
+	entries, err := archive.Entries()
+	if err != nil {
+		return err
+	}
+
+	var count int
+	var freed int64
+	for _, e := range entries {
+		if known[e.Id] {
+			continue
+		}
+
+		if gc.dryRun {
+			fmt.Printf("Would remove %s (%s)\n", e.Id, formatSize(e.Size))
+		} else {
+			rmErr := errors.Join(archive.Remove(e.Id), ctx.index.Remove(e.Id))
+			if rmErr != nil {
+				err = errors.Join(err, rmErr)
+				continue
+			}
+
+			fmt.Printf("Removed %s (%s)\n", e.Id, formatSize(e.Size))
+		}
+
+		count++
+		freed += e.Size
+	}
+
+	if gc.dryRun {
+		fmt.Printf("Would remove %d orphaned archives, %s\n", count, formatSize(freed))
+		return err
+	}
+
+	fmt.Printf("Removed %d orphaned archives, freed %s\n", count, formatSize(freed))
+	return err
+}
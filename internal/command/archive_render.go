@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	archiveRenderName      = "render"
+	archiveRenderUsage     = "anchor archive render [LABEL...]"
+	archiveRenderShortHelp = "wrap existing archives with the current template"
+	archiveRenderLongHelp  = `  Renders every archived page under [LABEL] and its sub-labels again with the current
+  archive template, e.g. after changing it, without fetching the pages again. If no
+  label is provided, all labels are rendered.
+
+  A custom template can be placed at $XDG_CONFIG_HOME/anchor/archive.html. It is an
+  html/template with access to {{.Title}}, {{.URL}}, {{.Byline}}, {{.Fetched}} and
+  {{.Content}}.`
+)
+
+var (
+	ErrRenderFailed = errors.New("failed to render archives")
+)
+
+type archiveRenderCmd struct{}
+
+func (render *archiveRenderCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	return &ff.Command{
+		Name:      archiveRenderName,
+		Usage:     archiveRenderUsage,
+		ShortHelp: archiveRenderShortHelp,
+		LongHelp:  archiveRenderLongHelp,
+		Flags:     ff.NewFlagSet("render").SetParent(parent),
+		Exec: func(ctx context.Context, args []string) error {
+			return render.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (*archiveRenderCmd) handle(ctx appContext, args []string) error {
+	names, bookmarks, err := readLabels(args)
+	if err != nil {
+		return err
+	}
+
+	var rendered, failed int
+	for _, name := range names {
+		for _, bk := range bookmarks[name] {
+			snapshots, err := archive.Snapshots(bk.Id())
+			if err != nil {
+				return err
+			}
+
+			for _, s := range snapshots {
+				if !s.HTML() {
+					continue
+				}
+
+				err = ctx.archiver.Render(s, bk.Title(), bk.URL())
+				if err != nil {
+					failed++
+					fmt.Fprintf(os.Stderr, "Failed %s: %v\n", s.Path, err)
+					continue
+				}
+
+				rendered++
+			}
+		}
+	}
+
+	fmt.Printf("Rendered %d archives\n", rendered)
+	if failed > 0 {
+		return fmt.Errorf("%d: %w", failed, ErrRenderFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/muesli/reflow/truncate"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	archiveStatsName      = "stats"
+	archiveStatsUsage     = "anchor archive stats [FLAGS]"
+	archiveStatsShortHelp = "show disk usage of archives"
+	archiveStatsLongHelp  = `  Prints the total size and count of archives, the largest ones and, for every label,
+  how many of its bookmarks are archived and how much space they take. Archives of
+  bookmarks that no longer exist are reported as orphans, see "anchor archive gc".`
+)
+
+const (
+	stdArchiveLargest = 10
+	stdTitleWidth     = 50
+)
+
+type archiveStatsCmd struct {
+	largest int
+}
+
+func (stats *archiveStatsCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("stats").SetParent(parent)
+	flags.IntVar(&stats.largest, 'n', "largest", stdArchiveLargest, "number of largest archives to list")
+
+	return &ff.Command{
+		Name:      archiveStatsName,
+		Usage:     archiveStatsUsage,
+		ShortHelp: archiveStatsShortHelp,
+		LongHelp:  archiveStatsLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return stats.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (stats *archiveStatsCmd) handle(_ appContext, _ []string) error {
+	names, bookmarks, err := readLabels(nil)
+	if err != nil {
+		return err
+	}
+
+	entries, err := archive.Entries()
+	if err != nil {
+		return err
+	}
+
+	sizes := map[uuid.UUID]int64{}
+	var total int64
+	for _, e := range entries {
+		sizes[e.Id] = e.Size
+		total += e.Size
+	}
+
+	titles := map[uuid.UUID]string{}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "LABEL\tARCHIVED\tSIZE")
+	for _, name := range names {
+		var count int
+		var size int64
+		for _, bk := range bookmarks[name] {
+			titles[bk.Id()] = bk.Title()
+			if s, ok := sizes[bk.Id()]; ok {
+				count++
+				size += s
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\n", name, count, len(bookmarks[name]), formatSize(size))
+	}
+
+	var orphans int
+	var orphanSize int64
+	for _, e := range entries {
+		if _, ok := titles[e.Id]; !ok {
+			orphans++
+			orphanSize += e.Size
+		}
+	}
+
+	if orphans > 0 {
+		fmt.Fprintf(w, "(orphans)\t%d\t%s\n", orphans, formatSize(orphanSize))
+	}
+
+	fmt.Fprintf(w, "\nTOTAL\t%d\t%s\n", len(entries), formatSize(total))
+
+	slices.SortFunc(entries, func(a, b archive.Entry) int {
+		return cmp.Compare(b.Size, a.Size)
+	})
+
+	if stats.largest > 0 && len(entries) > 0 {
+		fmt.Fprintln(w, "\nLARGEST\tSIZE\tID")
+		for _, e := range entries[:min(stats.largest, len(entries))] {
+			title := truncate.StringWithTail(cmp.Or(titles[e.Id], "(orphan)"), stdTitleWidth, "…")
+			fmt.Fprintf(w, "%s\t%s\t%s\n", title, formatSize(e.Size), e.Id)
+		}
+	}
+
+	return w.Flush()
+}
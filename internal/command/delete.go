@@ -38,11 +38,11 @@ func (del *deleteCmd) manifest(parent *ff.FlagSet) *ff.Command {
 	}
 }
 
-func (del *deleteCmd) handle(_ appContext, args []string) (err error) {
+func (del *deleteCmd) handle(ctx appContext, args []string) (err error) {
 	ok := output.Confirm(msgDeleteLabel)
 	if !ok {
 		return nil
 	}
 
-	return label.Remove(config.DataDirPath(), args)
+	return label.Remove(config.DataDirPath(), args, ctx.index)
 }
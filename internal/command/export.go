@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	exportName      = "export"
+	exportUsage     = "anchor export <SUBCOMMAND>"
+	exportShortHelp = "export bookmarks to other formats"
+	exportLongHelp  = `  Exports bookmarks and their archives to formats other applications can read.
+
+SUBCOMMANDS
+  epub  package the archives of a label as an EPUB
+`
+)
+
+type exportCmd struct{}
+
+func (*exportCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("export").SetParent(parent)
+
+	return &ff.Command{
+		Name:      exportName,
+		Usage:     exportUsage,
+		ShortHelp: exportShortHelp,
+		LongHelp:  exportLongHelp,
+		Flags:     flags,
+		Subcommands: []*ff.Command{
+			(&exportEpubCmd{}).manifest(flags),
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/epub"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	exportEpubName      = "epub"
+	exportEpubUsage     = "anchor export epub [FLAGS] <LABEL...>"
+	exportEpubShortHelp = "package the archives of a label as an EPUB"
+	exportEpubLongHelp  = `  Packages the latest archive of every bookmark under <LABEL> and its sub-labels into an
+  EPUB 3 file, one chapter per page in the order of the label files. The table of contents
+  lists the bookmark titles and the book is titled after the label.
+
+  Bookmarks without an archive or archived as PDFs or images are skipped. Images bundled
+  with the archives are included, remote ones are left out so the book reads offline.
+
+  The file is written to <LABEL>.epub in the current directory unless -o is set.
+
+EXAMPLES
+  # Export the archives under label "programming" to programming.epub
+  anchor export epub programming
+
+  # Export the archives under label "programming.go" to a custom path
+  anchor export epub -o ~/books/go.epub programming go
+`
+)
+
+var (
+	ErrMissingLabel    = errors.New("missing label")
+	ErrNoArchivedPages = errors.New("no archived pages under label")
+)
+
+type exportEpubCmd struct {
+	output   string
+	language string
+}
+
+func (exp *exportEpubCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("epub").SetParent(parent)
+	flags.StringVar(&exp.output, 'o', "output", "", "path of the EPUB file")
+	flags.StringVar(&exp.language, 0, "language", "en", "language of the book")
+
+	return &ff.Command{
+		Name:      exportEpubName,
+		Usage:     exportEpubUsage,
+		ShortHelp: exportEpubShortHelp,
+		LongHelp:  exportEpubLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return exp.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (exp *exportEpubCmd) handle(_ appContext, args []string) (err error) {
+	if len(args) == 0 {
+		return ErrMissingLabel
+	}
+
+	names, bookmarks, err := readLabels(args)
+	if err != nil {
+		return err
+	}
+
+	title := strings.Join(args, config.StdLabelSeparator)
+	book := epub.Book{
+		Id:       "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte("anchor:"+title)).String(),
+		Title:    title,
+		Language: exp.language,
+		Modified: time.Now(),
+	}
+
+	var skipped int
+	for _, name := range names {
+		for _, bk := range bookmarks[name] {
+			ch, ok, err := chapter(bk)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				skipped++
+				continue
+			}
+
+			book.Chapters = append(book.Chapters, ch)
+		}
+	}
+
+	if len(book.Chapters) == 0 {
+		return fmt.Errorf("%q: %w", title, ErrNoArchivedPages)
+	}
+
+	path := exp.output
+	if path == "" {
+		path = title + ".epub"
+	}
+
+	fh, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = errors.Join(err, fh.Close())
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	err = book.Write(fh)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d archives to %s, skipped %d bookmarks\n", len(book.Chapters), path, skipped)
+	return nil
+}
+
+// chapter returns the latest HTML or text archive of bk
+// as a chapter and reports false if there is none.
+func chapter(bk *model.Bookmark) (epub.Chapter, bool, error) {
+	latest, ok := archive.Latest(bk.Id())
+	if !ok || (!latest.HTML() && !latest.Text()) {
+		return epub.Chapter{}, false, nil
+	}
+
+	res := epub.Chapter{
+		Title:  bk.Title(),
+		URL:    bk.URL(),
+		Assets: os.DirFS(filepath.Dir(latest.Path)),
+	}
+
+	if latest.Text() {
+		text, err := latest.ReadText()
+		if err != nil {
+			return epub.Chapter{}, false, err
+		}
+
+		res.Content = "<pre>" + html.EscapeString(text) + "</pre>"
+		return res, true, nil
+	}
+
+	page, err := os.ReadFile(latest.Path)
+	if err != nil {
+		return epub.Chapter{}, false, err
+	}
+
+	res.Content = string(archive.Content(page))
+	return res, true, nil
+}
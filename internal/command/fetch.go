@@ -0,0 +1,143 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	fetchName      = "fetch"
+	fetchUsage     = "anchor fetch"
+	fetchShortHelp = "download the latest changes from the remote"
+	fetchLongHelp  = `  Downloads the changes of the remote without applying them, the next command applies
+  them without going over the network. Commands that only read bookmarks run this in the
+  background whenever the last successful fetch is older than the configured "fetch-interval".
+
+  Has no effect if the backing storage is set to "local".
+`
+)
+
+var (
+	ErrInvalidFetchInterval = errors.New("fetch interval must not be negative")
+)
+
+type Updater interface {
+	Fetch() error
+	Merge() error
+}
+
+type fetchCmd struct{}
+
+func (f *fetchCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("fetch").SetParent(parent)
+
+	return &ff.Command{
+		Name:      fetchName,
+		Usage:     fetchUsage,
+		ShortHelp: fetchShortHelp,
+		LongHelp:  fetchLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return f.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (f *fetchCmd) handle(ctx appContext, _ []string) error {
+	updater, ok := ctx.storer.(Updater)
+	if !ok || ctx.offline {
+		return nil
+	}
+
+	err := updater.Fetch()
+	if err != nil {
+		return err
+	}
+
+	return markFetched()
+}
+
+// update brings in the changes of the remote. The remote is only fetched
+// if the last successful fetch is older than interval, in the background if
+// wait is false, otherwise the changes fetched last time are merged locally.
+func update(updater Updater, interval time.Duration, wait bool) error {
+	stale := time.Since(readStamp(config.FetchFilePath())) >= interval
+	switch {
+	case stale && !wait:
+		// Commands run while a background fetch is still in progress,
+		// or shortly after it failed, do not spawn another one.
+		if time.Since(readStamp(config.FetchAttemptFilePath())) < interval {
+			break
+		}
+
+		err := writeStamp(config.FetchAttemptFilePath())
+		if err != nil {
+			return err
+		}
+
+		backgroundFetch()
+	case stale:
+		err := updater.Fetch()
+		if err != nil {
+			return err
+		}
+
+		err = markFetched()
+		if err != nil {
+			return err
+		}
+	}
+
+	return updater.Merge()
+}
+
+// backgroundFetch runs anchor fetch in a separate process that outlives
+// the current one. Failures are ignored, the next command tries again.
+func backgroundFetch() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	cmd := exec.Command(exe, fetchName)
+	if cmd.Start() == nil {
+		_ = cmd.Process.Release()
+	}
+}
+
+// markFetched stores the time of the last successful fetch.
+func markFetched() error {
+	return writeStamp(config.FetchFilePath())
+}
+
+// readStamp returns the time stored in path or
+// the zero time if there is none or it cannot be read.
+func readStamp(path string) time.Time {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	res, err := time.Parse(time.RFC3339, strings.TrimSpace(string(content)))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return res
+}
+
+func writeStamp(path string) error {
+	err := os.MkdirAll(config.StateDirPath(), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)+"\n"), config.StdFileMode)
+}
@@ -0,0 +1,200 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/loghinalexandru/anchor/internal/search"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	grepName      = "grep"
+	grepUsage     = "anchor grep [FLAGS] <TERMS...>"
+	grepShortHelp = "search the content of archived pages"
+	grepLongHelp  = `  Searches the text of the latest archive of every bookmark for <TERMS> and prints the
+  matching bookmarks, best match first, together with a snippet of the page. Terms are
+  matched regardless of case and word endings e.g. "running" also matches "runs".
+
+  Archives are added to a local index as they are created. Archives changed or removed
+  outside of anchor are picked up on the next search, use --reindex to rebuild the whole
+  index from scratch.
+
+  The search can be narrowed down to a label and its sub-labels with the -l flag.
+
+EXAMPLES
+  # Search every archived page
+  anchor grep garbage collector
+
+  # Show the 3 best matches under label "programming"
+  anchor grep -l programming -n 3 generics
+`
+)
+
+const (
+	stdGrepLimit = 10
+	snippetWords = 24
+)
+
+var (
+	ErrInvalidLimit = errors.New("limit must be greater than zero")
+)
+
+type grepCmd struct {
+	labels  []string
+	limit   int
+	reindex bool
+}
+
+func (g *grepCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("grep").SetParent(parent)
+	flags.StringSetVar(&g.labels, 'l', "label", "search only under labels in order of appearance")
+	flags.IntVar(&g.limit, 'n', "limit", stdGrepLimit, "maximum number of results")
+	flags.BoolVar(&g.reindex, 0, "reindex", "rebuild the index from all archives before searching")
+
+	return &ff.Command{
+		Name:      grepName,
+		Usage:     grepUsage,
+		ShortHelp: grepShortHelp,
+		LongHelp:  grepLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return g.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (g *grepCmd) handle(ctx appContext, args []string) error {
+	query := strings.Join(args, " ")
+	if query == "" {
+		return ErrMissingQuery
+	}
+
+	if g.limit <= 0 {
+		return ErrInvalidLimit
+	}
+
+	if g.reindex {
+		err := ctx.index.Reset()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reconcile against every label so a narrowed
+	// search does not drop documents of the others.
+	_, all, err := readLabels(nil)
+	if err != nil {
+		return err
+	}
+
+	err = reconcile(ctx, all)
+	if err != nil {
+		return err
+	}
+
+	_, bookmarks, err := readLabels(g.labels)
+	if err != nil {
+		return err
+	}
+
+	known := map[uuid.UUID]*model.Bookmark{}
+	for _, bks := range bookmarks {
+		for _, bk := range bks {
+			known[bk.Id()] = bk
+		}
+	}
+
+	results, err := ctx.index.Search(query)
+	if err != nil {
+		return err
+	}
+
+	mark := func(in string) string {
+		return style.Match().Render(in)
+	}
+
+	var count int
+	for _, r := range results {
+		bk, ok := known[r.Id]
+		if !ok {
+			continue
+		}
+
+		if count == g.limit {
+			break
+		}
+
+		if count > 0 {
+			fmt.Println()
+		}
+
+		fmt.Println(style.Heading().Render(bk.Title()))
+		fmt.Println(style.Muted().Render(bk.URL()))
+
+		text, err := archive.Snapshot{Path: r.Path}.ReadText()
+		if err == nil {
+			if snippet := search.Snippet(text, query, snippetWords, mark); snippet != "" {
+				fmt.Println(snippet)
+			}
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		return fmt.Errorf("%q: %w", query, ErrNoMatch)
+	}
+
+	return nil
+}
+
+// reconcile brings the index in line with the latest snapshot of every
+// bookmark, dropping documents of archives that were removed or replaced
+// and adding the ones that were never indexed.
+func reconcile(ctx appContext, bookmarks map[string][]*model.Bookmark) error {
+	docs, err := ctx.index.Docs()
+	if err != nil {
+		return err
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, bks := range bookmarks {
+		for _, bk := range bks {
+			id := bk.Id()
+			seen[id] = true
+
+			latest, ok := archive.Latest(id)
+			if !ok || (!latest.HTML() && !latest.Text()) {
+				err = errors.Join(err, ctx.index.Remove(id))
+				continue
+			}
+
+			if docs[id] == latest.Path {
+				continue
+			}
+
+			text, textErr := latest.ReadText()
+			if textErr != nil {
+				err = errors.Join(err, textErr)
+				continue
+			}
+
+			err = errors.Join(err, ctx.index.Add(id, latest.Path, text))
+		}
+	}
+
+	for id := range docs {
+		if !seen[id] {
+			err = errors.Join(err, ctx.index.Remove(id))
+		}
+	}
+
+	return err
+}
@@ -19,12 +19,20 @@ const (
   for any other input. If you want to use something else provide
   what the backing storage requires as arguments.
 
+  For git storage the URL of the remote is optional. Without it the
+  repository is only created locally, use "anchor remote add" to
+  attach one later. Bookmarks already in the home directory, e.g.
+  when switching from local storage, are committed and kept.
+
 EXAMPLES
    # Setup local storage
    anchor init
 
    # Setup git storage
    anchor init git@github.com:loghinalexandru/anchor.git
+
+   # Setup git storage without a remote
+   anchor init
 `
 )
 
@@ -0,0 +1,133 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/loghinalexandru/anchor/internal/command/util/label"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/loghinalexandru/anchor/internal/storage"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	logName      = "log"
+	logUsage     = "anchor log [FLAGS] [LABEL]"
+	logShortHelp = "show the history of bookmark changes"
+	logLongHelp  = `  Lists the commits of the git backing storage, newest first, together with the bookmarks
+  each of them added, removed or renamed. Bookmarks whose URL or comment changed are listed
+  as edited. Removed bookmarks are shown with their id, or URL if saved without one, so
+  they can be brought back with "anchor restore --id".
+
+  If [LABEL] is passed only the commits changing that label or its sub-labels are listed.
+
+  Requires the backing storage to be set to "git".
+
+EXAMPLES
+  # Show the last 5 commits
+  anchor log -n 5
+
+  # Show the history of label "programming" and its sub-labels
+  anchor log programming
+`
+)
+
+const (
+	stdLogLimit = 20
+	logLayout   = time.DateTime
+)
+
+var (
+	ErrNoHistory = errors.New("history requires git storage")
+)
+
+type Historian interface {
+	Log(limit int, match func(label string) bool) ([]storage.Revision, error)
+}
+
+type logCmd struct {
+	limit int
+}
+
+func (l *logCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("log").SetParent(parent)
+	flags.IntVar(&l.limit, 'n', "limit", stdLogLimit, "maximum number of commits")
+
+	return &ff.Command{
+		Name:      logName,
+		Usage:     logUsage,
+		ShortHelp: logShortHelp,
+		LongHelp:  logLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return l.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (l *logCmd) handle(ctx appContext, args []string) error {
+	historian, ok := ctx.storer.(Historian)
+	if !ok {
+		return ErrNoHistory
+	}
+
+	if l.limit <= 0 {
+		return ErrInvalidLimit
+	}
+
+	var match func(string) bool
+	if len(args) > 0 {
+		var err error
+		match, err = label.Matcher(label.Format(args))
+		if err != nil {
+			return err
+		}
+	}
+
+	revisions, err := historian.Log(l.limit, match)
+	if err != nil {
+		return err
+	}
+
+	for i, rev := range revisions {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		printRevision(rev)
+	}
+
+	return nil
+}
+
+func printRevision(rev storage.Revision) {
+	subject, _, _ := strings.Cut(strings.TrimSpace(rev.Message), "\n")
+	fmt.Printf("%s %s\n", style.Heading().Render(rev.Hash[:7]), subject)
+	fmt.Println(style.Muted().Render(rev.When.Local().Format(logLayout) + " • " + rev.Author))
+
+	for _, c := range rev.Changes {
+		fmt.Println("  " + formatChange(c))
+	}
+}
+
+func formatChange(c storage.Change) string {
+	switch c.Kind {
+	case storage.Added:
+		return style.Inserted().Render("+ "+c.New.Title()) + " " + style.Muted().Render(c.Label)
+	case storage.Removed:
+		return style.Deleted().Render("- "+c.Old.Title()) + " " + style.Muted().Render(c.Label+" "+c.Key)
+	case storage.Reordered:
+		return "~ " + style.Muted().Render(c.Label+" reordered")
+	case storage.Touched:
+		return "~ " + style.Muted().Render(c.Label+" changed")
+	}
+
+	if c.Old.Title() != c.New.Title() {
+		return "~ " + c.Old.Title() + " → " + c.New.Title() + " " + style.Muted().Render(c.Label)
+	}
+
+	return "~ " + c.New.Title() + " " + style.Muted().Render(c.Label+" edited")
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/loghinalexandru/anchor/internal/command/util/label"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/peterbourgon/ff/v4"
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	openName      = "open"
+	openUsage     = "anchor open [FLAGS] <QUERY>"
+	openShortHelp = "fuzzy find a bookmark and open it"
+	openLongHelp  = `  Searches the title and URL of every bookmark for the best fuzzy match of <QUERY>
+  and launches it with the configured opener. If no opener is configured, the default
+  handler of the system is used.
+
+  The search can be narrowed down to a label and its sub-labels with the -l flag.
+
+EXAMPLES
+  # Open the best match across all labels
+  anchor open go spec
+
+  # Open the best match under label "programming"
+  anchor open -l programming spec
+`
+)
+
+var (
+	ErrMissingQuery = errors.New("missing search query")
+	ErrNoMatch      = errors.New("no bookmark matches query")
+)
+
+type openCmd struct {
+	labels []string
+}
+
+func (o *openCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("open").SetParent(parent)
+	flags.StringSetVar(&o.labels, 'l', "label", "search only under labels in order of appearance")
+
+	return &ff.Command{
+		Name:      openName,
+		Usage:     openUsage,
+		ShortHelp: openShortHelp,
+		LongHelp:  openLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return o.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (o *openCmd) handle(ctx appContext, args []string) error {
+	query := strings.Join(args, " ")
+	if query == "" {
+		return ErrMissingQuery
+	}
+
+	names, err := label.List(config.DataDirPath(), o.labels)
+	if err != nil {
+		return err
+	}
+
+	var bookmarks searchSource
+	for _, name := range names {
+		bks, err := label.Bookmarks(config.DataDirPath(), name)
+		if err != nil {
+			return err
+		}
+
+		bookmarks = append(bookmarks, bks...)
+	}
+
+	matches := fuzzy.FindFrom(query, bookmarks)
+	if len(matches) == 0 {
+		return fmt.Errorf("%q: %w", query, ErrNoMatch)
+	}
+
+	bk := bookmarks[matches[0].Index]
+	fmt.Printf("Opening %q\n", bk.Title())
+
+	err = ctx.opener.Open(bk.URL(), bk.Title())
+	if err != nil {
+		return err
+	}
+
+	history, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	history.Touch(bk.Id())
+	return writeHistory(history)
+}
+
+// searchSource implements fuzzy.Source on both title and URL.
+type searchSource []*model.Bookmark
+
+func (s searchSource) String(i int) string {
+	return s[i].Title() + " " + s[i].URL()
+}
+
+func (s searchSource) Len() int {
+	return len(s)
+}
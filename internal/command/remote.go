@@ -0,0 +1,62 @@
+package command
+
+import (
+	"errors"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	remoteName      = "remote"
+	remoteUsage     = "anchor remote <SUBCOMMAND>"
+	remoteShortHelp = "manage the remote of the git backing storage"
+	remoteLongHelp  = `  Attaches a remote to a git backing storage initialized without one or changes where
+  the existing one points to. The remote name is taken from "git.remote" (default: origin).
+
+SUBCOMMANDS
+  add      attach a remote and bring in its changes
+  set-url  change the URL of the remote
+`
+)
+
+var (
+	ErrMissingURL = errors.New("missing remote URL")
+	ErrNoRemotes  = errors.New("remotes require git storage")
+)
+
+type Remoter interface {
+	AddRemote(rawURL string) error
+	SetRemoteURL(rawURL string) error
+}
+
+type remoteCmd struct{}
+
+func (*remoteCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("remote").SetParent(parent)
+
+	return &ff.Command{
+		Name:      remoteName,
+		Usage:     remoteUsage,
+		ShortHelp: remoteShortHelp,
+		LongHelp:  remoteLongHelp,
+		Flags:     flags,
+		Subcommands: []*ff.Command{
+			(&remoteAddCmd{}).manifest(flags),
+			(&remoteSetURLCmd{}).manifest(flags),
+		},
+	}
+}
+
+// remoter returns the storer of ctx as a Remoter and the URL from args.
+func remoter(ctx appContext, args []string) (Remoter, string, error) {
+	r, ok := ctx.storer.(Remoter)
+	if !ok {
+		return nil, "", ErrNoRemotes
+	}
+
+	if len(args) == 0 {
+		return nil, "", ErrMissingURL
+	}
+
+	return r, args[0], nil
+}
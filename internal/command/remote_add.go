@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	remoteAddName      = "add"
+	remoteAddUsage     = "anchor remote add <URL>"
+	remoteAddShortHelp = "attach a remote and bring in its changes"
+	remoteAddLongHelp  = `  Attaches the repository at <URL> as the remote of a git backing storage initialized
+  without one. Bookmarks already on the remote are merged with the local ones, the local
+  commits are pushed on the next sync.
+
+EXAMPLES
+  # Attach an empty repository and push the local history to it
+  anchor remote add git@github.com:loghinalexandru/bookmarks.git
+  anchor sync
+`
+)
+
+type remoteAddCmd struct{}
+
+func (add *remoteAddCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	return &ff.Command{
+		Name:      remoteAddName,
+		Usage:     remoteAddUsage,
+		ShortHelp: remoteAddShortHelp,
+		LongHelp:  remoteAddLongHelp,
+		Flags:     ff.NewFlagSet("add").SetParent(parent),
+		Exec: func(ctx context.Context, args []string) error {
+			return add.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (add *remoteAddCmd) handle(ctx appContext, args []string) error {
+	r, url, err := remoter(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	err = r.AddRemote(url)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added remote %s\n", url)
+	return nil
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	remoteSetURLName      = "set-url"
+	remoteSetURLUsage     = "anchor remote set-url <URL>"
+	remoteSetURLShortHelp = "change the URL of the remote"
+	remoteSetURLLongHelp  = `  Points the remote of the git backing storage to <URL>, e.g. after the repository moved
+  or to switch from HTTPS to SSH. The history is left as it is.
+
+EXAMPLES
+  # Switch the remote to SSH
+  anchor remote set-url git@github.com:loghinalexandru/bookmarks.git
+`
+)
+
+type remoteSetURLCmd struct{}
+
+func (set *remoteSetURLCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	return &ff.Command{
+		Name:      remoteSetURLName,
+		Usage:     remoteSetURLUsage,
+		ShortHelp: remoteSetURLShortHelp,
+		LongHelp:  remoteSetURLLongHelp,
+		Flags:     ff.NewFlagSet("set-url").SetParent(parent),
+		Exec: func(ctx context.Context, args []string) error {
+			return set.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (set *remoteSetURLCmd) handle(ctx appContext, args []string) error {
+	r, url, err := remoter(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	err = r.SetRemoteURL(url)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Remote now points to %s\n", url)
+	return nil
+}
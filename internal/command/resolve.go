@@ -0,0 +1,35 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/loghinalexandru/anchor/internal/output"
+	"github.com/loghinalexandru/anchor/internal/storage"
+)
+
+const (
+	msgKeepVersion = "Keep which version?"
+)
+
+var (
+	ErrUnresolvedConflict = errors.New("merge conflict left unresolved")
+)
+
+// resolveConflict asks which version to keep of a bookmark
+// edited differently both locally and on the remote.
+func resolveConflict(c storage.Conflict) (*model.Bookmark, error) {
+	fmt.Printf("Bookmark %q under label %q changed both locally and on the remote\n", c.Base.Title(), c.Label)
+	fmt.Printf("  local:  %s", c.Ours)
+	fmt.Printf("  remote: %s", c.Theirs)
+
+	switch output.Choose(msgKeepVersion, "local", "remote") {
+	case 0:
+		return c.Ours, nil
+	case 1:
+		return c.Theirs, nil
+	default:
+		return nil, fmt.Errorf("%s: %q: %w", c.Label, c.Base.Title(), ErrUnresolvedConflict)
+	}
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/loghinalexandru/anchor/internal/command/util/label"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/loghinalexandru/anchor/internal/output"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	restoreName      = "restore"
+	restoreUsage     = "anchor restore [FLAGS] <COMMIT> [LABEL]"
+	restoreShortHelp = "bring back a label or a bookmark from history"
+	restoreLongHelp  = `  Brings back the label file [LABEL] as it was at <COMMIT>, replacing its current content.
+  With --id only the bookmark with that id is brought back and appended to the label it
+  was in at <COMMIT>, or to [LABEL] if passed. Ids of removed bookmarks are listed by
+  "anchor log".
+
+  <COMMIT> is a commit hash, a prefix of one or a revision such as HEAD~2. The result is
+  committed right away and pushed on the next sync. Prompts for confirmation before
+  replacing a label.
+
+  Requires the backing storage to be set to "git".
+
+EXAMPLES
+  # Bring back label "programming" as it was before the last commit
+  anchor restore HEAD~1 programming
+
+  # Bring back a single deleted bookmark
+  anchor restore --id 0192f0a0-0000-7000-8000-00000000000a 3f2a9c1
+`
+)
+
+const (
+	msgRestoreLabel = "You are about to replace label %q with its version from %s. Proceed?"
+)
+
+var (
+	ErrMissingCommit = errors.New("missing commit")
+)
+
+type Restorer interface {
+	RestoreLabel(rev string, name string) error
+	RestoreBookmark(rev string, id string, name string) (*model.Bookmark, string, error)
+}
+
+type restoreCmd struct {
+	id string
+}
+
+func (r *restoreCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("restore").SetParent(parent)
+	flags.StringVar(&r.id, 0, "id", "", "restore only the bookmark with this id")
+
+	return &ff.Command{
+		Name:      restoreName,
+		Usage:     restoreUsage,
+		ShortHelp: restoreShortHelp,
+		LongHelp:  restoreLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return r.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (r *restoreCmd) handle(ctx appContext, args []string) error {
+	restorer, ok := ctx.storer.(Restorer)
+	if !ok {
+		return ErrNoHistory
+	}
+
+	if len(args) == 0 {
+		return ErrMissingCommit
+	}
+
+	rev, labels := args[0], label.Format(args[1:])
+	name, err := label.Name(labels)
+	if err != nil {
+		return err
+	}
+
+	if r.id != "" {
+		if len(labels) == 0 {
+			name = ""
+		}
+
+		bk, name, err := restorer.RestoreBookmark(rev, r.id, name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored %q to %s\n", bk.Title(), name)
+		return nil
+	}
+
+	if ok := output.Confirm(fmt.Sprintf(msgRestoreLabel, name, rev)); !ok {
+		return nil
+	}
+
+	err = restorer.RestoreLabel(rev, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %s\n", name)
+	return nil
+}
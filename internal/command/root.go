@@ -4,48 +4,57 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"html/template"
 	"io/fs"
 	"net/http"
 	"os"
-	"strings"
+	"time"
 
+	"github.com/loghinalexandru/anchor/internal/archive"
 	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/opener"
 	"github.com/loghinalexandru/anchor/internal/output"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea"
+	"github.com/loghinalexandru/anchor/internal/search"
 	"github.com/loghinalexandru/anchor/internal/storage"
 	"github.com/peterbourgon/ff/v4"
-	"github.com/peterbourgon/ff/v4/ffyaml"
 )
 
 const (
 	rootName        = "anchor"
 	rootUsage       = "anchor <SUBCOMMAND>"
 	msgUpdateFailed = "Failed pulling latest changes. Continue operation?"
+	msgAutoSync     = "changes are saved locally, run anchor sync to retry: %w"
 )
 
-type Updater interface {
-	Update() error
-}
-
 // appContext is a context.Context wrapper for
 // type safety and to avoid key-value pairs.
 type appContext struct {
 	context.Context
-	kind     storage.Kind
-	storer   storage.Storer
-	syncMode string
-	client   *http.Client
-	template *template.Template
+	kind      storage.Kind
+	storer    storage.Storer
+	syncMode  storage.SyncMode
+	offline   bool
+	interval  time.Duration
+	sortMode  bubbletea.SortMode
+	labelSort map[string]bubbletea.SortMode
+	keys      bubbletea.KeyMap
+	opener    *opener.Opener
+	client    *http.Client
+	archiver  *archive.Archiver
+	index     *search.Index
+	gitOpts   []storage.Option
 }
 
 type rootCmd struct {
-	cmd *ff.Command
+	cmd     *ff.Command
+	offline bool
 }
 
 func newRoot() *rootCmd {
 	root := &rootCmd{}
 
 	rootFlags := ff.NewFlagSet("anchor")
+	rootFlags.BoolVar(&root.offline, 0, "offline", "work on the local copy without pulling or pushing changes")
 
 	root.cmd = &ff.Command{
 		Name:  rootName,
@@ -59,7 +68,16 @@ func newRoot() *rootCmd {
 		(&addCmd{}).manifest(rootFlags),
 		(&deleteCmd{}).manifest(rootFlags),
 		(&treeCmd{}).manifest(rootFlags),
+		(&openCmd{}).manifest(rootFlags),
+		(&archiveCmd{}).manifest(rootFlags),
+		(&grepCmd{}).manifest(rootFlags),
+		(&exportCmd{}).manifest(rootFlags),
 		(&syncCmd{}).manifest(rootFlags),
+		(&fetchCmd{}).manifest(rootFlags),
+		(&remoteCmd{}).manifest(rootFlags),
+		(&storageCmd{}).manifest(rootFlags),
+		(&logCmd{}).manifest(rootFlags),
+		(&restoreCmd{}).manifest(rootFlags),
 		(&importCmd{}).manifest(rootFlags),
 		(&versionCmd{}).manifest(rootFlags),
 	}
@@ -84,68 +102,86 @@ func (root *rootCmd) handle(ctx context.Context, args []string) (err error) {
 		}
 	}()
 
-	// Configure global HTML template.
-	style := []string{
-		"max-width: 40em",
-		"margin-right: 10%",
-		"margin-left: 10%",
-		"margin-top:7%",
-		"margin-bottom: 7%",
-	}
-	tmpl, _ := template.New("root").Parse(fmt.Sprintf(`<div style="%s;">{{.}}</div>`, strings.Join(style, ";")))
-
 	// Initialize appContext with sensible defaults.
 	appCtx := appContext{
 		Context:  ctx,
 		kind:     storage.Local,
-		syncMode: "always",
+		syncMode: storage.AlwaysSync,
+		offline:  root.offline,
+		interval: config.StdFetchInterval,
+		sortMode: bubbletea.Manual,
 		client:   &http.Client{Timeout: config.StdHttpTimeout},
-		template: tmpl,
+		index:    search.New(config.IndexFilePath()),
 	}
 
-	// Config file might not exist, ignore errors if so.
-	_ = ffyaml.Parse(fh, func(key, value string) error {
-		switch key {
-		case config.StdSyncModeKey:
-			appCtx.syncMode = value
-		case config.StdStorageKey:
-			appCtx.kind = storage.Parse(value)
-		}
-
-		return nil
-	})
+	err = appCtx.configure(fh)
+	if err != nil {
+		return err
+	}
 
 	// Initialize storer after config was read to not miss
 	// any custom values e.g. path.
-	appCtx.storer = storage.New(appCtx.kind)
+	appCtx.storer = storage.New(appCtx.kind, appCtx.gitOpts...)
 
 	// Add appropriate middleware for each subcommand
 	for _, c := range root.cmd.Subcommands {
 		switch c.Name {
 		// Skip updateMiddleware for commands that
 		// do not need to fetch from remote.
-		case initName:
-			c.Exec = contextMiddleware(c.Exec)
-		case versionName:
-			c.Exec = contextMiddleware(c.Exec)
+		case initName, versionName, fetchName, remoteName:
+			wrapLocal(c)
+		// Commands changing bookmarks wait for the latest changes
+		// and push them right away in auto sync mode.
+		case addName, deleteName, importName, viewName, restoreName:
+			c.Exec = autoSyncMiddleware(c.Exec, appCtx)
+			wrap(c, appCtx, true)
+		case syncName, storageName:
+			wrap(c, appCtx, true)
 		default:
-			c.Exec = contextMiddleware(updaterMiddleware(c.Exec, appCtx))
+			wrap(c, appCtx, false)
 		}
 	}
 
-	return root.cmd.Run(appCtx)
+	// The index is only written if a command changed it.
+	return errors.Join(root.cmd.Run(appCtx), appCtx.index.Save())
+}
+
+// wrap adds the default middleware to c and all its nested subcommands.
+// Commands without Exec only group their subcommands and are left as is.
+func wrap(c *ff.Command, appCtx appContext, wait bool) {
+	if c.Exec != nil {
+		c.Exec = contextMiddleware(updaterMiddleware(c.Exec, appCtx, wait))
+	}
+
+	for _, sub := range c.Subcommands {
+		wrap(sub, appCtx, wait)
+	}
+}
+
+// wrapLocal adds only the context middleware to c and all its nested
+// subcommands, for commands that do not need to fetch from remote.
+func wrapLocal(c *ff.Command) {
+	if c.Exec != nil {
+		c.Exec = contextMiddleware(c.Exec)
+	}
+
+	for _, sub := range c.Subcommands {
+		wrapLocal(sub)
+	}
 }
 
 type handlerFunc func(ctx context.Context, args []string) error
 
-func updaterMiddleware(next handlerFunc, appCtx appContext) handlerFunc {
+// updaterMiddleware brings in the changes of the remote before next runs,
+// waiting for the fetch only if wait is set, see update.
+func updaterMiddleware(next handlerFunc, appCtx appContext, wait bool) handlerFunc {
 	updater, ok := appCtx.storer.(Updater)
-	if !ok || appCtx.syncMode != "always" {
+	if !ok || !appCtx.syncMode.Pulls() || appCtx.offline {
 		return next
 	}
 
 	return func(ctx context.Context, args []string) error {
-		err := updater.Update()
+		err := update(updater, appCtx.interval, wait)
 		if err != nil {
 			if ok := output.Confirm(msgUpdateFailed); !ok {
 				return err
@@ -156,6 +192,44 @@ func updaterMiddleware(next handlerFunc, appCtx appContext) handlerFunc {
 	}
 }
 
+// autoSyncMiddleware commits and pushes the changes made by next
+// with a message describing them if the sync mode is auto.
+func autoSyncMiddleware(next handlerFunc, appCtx appContext) handlerFunc {
+	syncer, canSync := appCtx.storer.(Syncer)
+	differ, canDiff := appCtx.storer.(Differ)
+	if !canSync || !canDiff || appCtx.syncMode != storage.AutoSync || appCtx.offline {
+		return next
+	}
+
+	return func(ctx context.Context, args []string) error {
+		err := next(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		changes, err := differ.Diff()
+		if err != nil {
+			return fmt.Errorf(msgAutoSync, err)
+		}
+
+		pending, err := syncer.Unpushed()
+		if err != nil {
+			return fmt.Errorf(msgAutoSync, err)
+		}
+
+		if len(changes) == 0 && pending == 0 {
+			return nil
+		}
+
+		_, err = syncer.Sync(describe(changes))
+		if err != nil {
+			return fmt.Errorf(msgAutoSync, err)
+		}
+
+		return nil
+	}
+}
+
 func contextMiddleware(next handlerFunc) handlerFunc {
 	return func(ctx context.Context, args []string) error {
 		res := make(chan error, 1)
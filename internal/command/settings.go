@@ -0,0 +1,151 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/opener"
+	"github.com/loghinalexandru/anchor/internal/output"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/loghinalexandru/anchor/internal/storage"
+	"github.com/peterbourgon/ff/v4/ffyaml"
+)
+
+// configure reads the config file from r and sets the values on appCtx.
+// The config file might not exist so parsing errors are ignored but
+// invalid values are collected and reported together.
+func (appCtx *appContext) configure(r io.Reader) error {
+	var cfgErr error
+	keys := map[string][]string{}
+	theme := style.StdTheme
+	themes := map[string]map[string]string{}
+	appCtx.labelSort = map[string]bubbletea.SortMode{}
+
+	var openerOpts []func(*opener.Opener)
+	appCtx.gitOpts = []storage.Option{
+		storage.WithPrompt(output.Password),
+		storage.WithResolver(resolveConflict),
+	}
+	archiveOpts := []func(*archive.Archiver){
+		archive.WithIndex(appCtx.index),
+	}
+	_ = ffyaml.Parse(r, func(key, value string) error {
+		switch key {
+		case config.StdSyncModeKey:
+			mode, err := storage.ParseSyncMode(value)
+			appCtx.syncMode = mode
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdFetchKey:
+			interval, err := time.ParseDuration(value)
+			if err == nil && interval < 0 {
+				err = fmt.Errorf("%q: %w", value, ErrInvalidFetchInterval)
+			}
+
+			appCtx.interval = interval
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdStorageKey:
+			appCtx.kind = storage.Parse(value)
+		case config.StdThemeKey:
+			theme = value
+		case config.StdSortKey:
+			mode, err := bubbletea.ParseSortMode(value)
+			appCtx.sortMode = mode
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdOpenerKey + ".command":
+			openerOpts = append(openerOpts, opener.WithCommand(value))
+		case config.StdOpenerKey + ".terminal":
+			terminal, err := strconv.ParseBool(value)
+			openerOpts = append(openerOpts, opener.WithTerminal(terminal))
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdArchiveKey + ".keep":
+			keep, err := strconv.Atoi(value)
+			archiveOpts = append(archiveOpts, archive.WithKeep(keep))
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdArchiveKey + ".assets":
+			mode, err := archive.ParseAssetMode(value)
+			archiveOpts = append(archiveOpts, archive.WithAssets(mode))
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdArchiveKey + ".styles":
+			styles, err := strconv.ParseBool(value)
+			archiveOpts = append(archiveOpts, archive.WithStyles(styles))
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdGitKey + ".auth":
+			mode, err := storage.ParseAuthMode(value)
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithAuth(mode))
+			cfgErr = errors.Join(cfgErr, err)
+		case config.StdGitKey + ".key":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithKey(value))
+		case config.StdGitKey + ".user":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithUser(value))
+		case config.StdGitKey + ".token-env":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithTokenEnv(value))
+		case config.StdGitKey + ".remote":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithRemote(value))
+		case config.StdGitKey + ".branch":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithBranch(value))
+		case config.StdGitKey + ".author.name":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithAuthorName(value))
+		case config.StdGitKey + ".author.email":
+			appCtx.gitOpts = append(appCtx.gitOpts, storage.WithAuthorEmail(value))
+		case config.StdArchiveKey + ".max-asset-size":
+			size, err := archive.ParseSize(value)
+			archiveOpts = append(archiveOpts, archive.WithMaxAssetSize(size))
+			cfgErr = errors.Join(cfgErr, err)
+		}
+
+		if action, ok := strings.CutPrefix(key, config.StdKeysKey+"."); ok {
+			for _, k := range strings.Split(value, ",") {
+				keys[action] = append(keys[action], strings.TrimSpace(k))
+			}
+		}
+
+		if rest, ok := strings.CutPrefix(key, config.StdThemesKey+"."); ok {
+			if name, field, ok := strings.Cut(rest, "."); ok {
+				if themes[name] == nil {
+					themes[name] = map[string]string{}
+				}
+
+				themes[name][field] = value
+			}
+		}
+
+		if domain, ok := strings.CutPrefix(key, config.StdOpenerKey+".domains."); ok {
+			openerOpts = append(openerOpts, opener.WithDomain(domain, value))
+		}
+
+		if label, ok := config.LabelSetting(key, config.StdSortKey); ok {
+			mode, err := bubbletea.ParseSortMode(value)
+			appCtx.labelSort[label] = mode
+			cfgErr = errors.Join(cfgErr, err)
+		}
+
+		return nil
+	})
+
+	var err error
+	appCtx.keys, err = bubbletea.NewKeyMap(keys)
+	cfgErr = errors.Join(cfgErr, err)
+
+	appCtx.opener = opener.New(openerOpts...)
+	tmpl, err := archive.LoadTemplate(config.TemplateFilePath())
+	archiveOpts = append(archiveOpts, archive.WithTemplate(tmpl))
+	cfgErr = errors.Join(cfgErr, err)
+	appCtx.archiver = archive.New(archiveOpts...)
+
+	t, err := style.Resolve(theme, themes)
+	cfgErr = errors.Join(cfgErr, err)
+	style.Use(t)
+
+	if cfgErr != nil {
+		return fmt.Errorf("invalid config: %w", cfgErr)
+	}
+
+	return nil
+}
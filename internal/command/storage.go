@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	storageName      = "storage"
+	storageUsage     = "anchor storage <SUBCOMMAND>"
+	storageShortHelp = "manage the backing storage"
+	storageLongHelp  = `  Manages the backing storage holding the label files.
+
+SUBCOMMANDS
+  migrate  move the bookmarks to another backing storage
+`
+)
+
+type storageCmd struct{}
+
+func (*storageCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("storage").SetParent(parent)
+
+	return &ff.Command{
+		Name:      storageName,
+		Usage:     storageUsage,
+		ShortHelp: storageShortHelp,
+		LongHelp:  storageLongHelp,
+		Flags:     flags,
+		Subcommands: []*ff.Command{
+			(&storageMigrateCmd{}).manifest(flags),
+		},
+	}
+}
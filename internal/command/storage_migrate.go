@@ -0,0 +1,275 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/loghinalexandru/anchor/internal/command/util/label"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/output"
+	"github.com/loghinalexandru/anchor/internal/storage"
+	"github.com/peterbourgon/ff/v4"
+)
+
+const (
+	storageMigrateName      = "migrate"
+	storageMigrateUsage     = "anchor storage migrate --to <KIND> [ARGS]"
+	storageMigrateShortHelp = "move the bookmarks to another backing storage"
+	storageMigrateLongHelp  = `  Moves every label file to the backing storage <KIND>, either "local" or "git", and
+  switches the config file over to it. [ARGS] are passed on to the new storage the same
+  way as for "anchor init", e.g. the URL of the remote for git storage.
+
+  The current home directory is renamed to a backup next to it and kept as it is, with
+  the git history if there was one. The label files are copied into a new home directory
+  which is then checked to hold the same bookmarks, by id, as before. If anything fails
+  the backup is put back in place.
+
+  Archives are kept apart from the backing storage and shared by all kinds of it, so they
+  are left where they are.
+
+EXAMPLES
+  # Start keeping history of local bookmarks with git
+  anchor storage migrate --to git
+
+  # Move local bookmarks to an empty remote repository
+  anchor storage migrate --to git git@github.com:loghinalexandru/bookmarks.git
+
+  # Go back to plain files
+  anchor storage migrate --to local
+`
+)
+
+const (
+	backupLayout   = "20060102-150405"
+	msgMigrate     = "Migrate %d bookmarks in %d labels from %s to %s storage?"
+	msgMigrateDone = "Migrated %d bookmarks in %d labels to %s storage, previous data kept in %s\n"
+)
+
+var (
+	ErrSameStorage       = errors.New("storage is already of this kind")
+	ErrMigrationMismatch = errors.New("bookmarks missing after migration")
+)
+
+// storageKeyRegexp matches the storage setting with an optional trailing comment.
+var storageKeyRegexp = regexp.MustCompile(`(?m)^` + config.StdStorageKey + `:[^#\n]*?([ \t]+#.*)?$`)
+
+type storageMigrateCmd struct {
+	to string
+}
+
+func (m *storageMigrateCmd) manifest(parent *ff.FlagSet) *ff.Command {
+	flags := ff.NewFlagSet("migrate").SetParent(parent)
+	flags.StringVar(&m.to, 0, "to", "", "kind of storage to migrate to: local or git")
+
+	return &ff.Command{
+		Name:      storageMigrateName,
+		Usage:     storageMigrateUsage,
+		ShortHelp: storageMigrateShortHelp,
+		LongHelp:  storageMigrateLongHelp,
+		Flags:     flags,
+		Exec: func(ctx context.Context, args []string) error {
+			return m.handle(ctx.(appContext), args)
+		},
+	}
+}
+
+func (m *storageMigrateCmd) handle(ctx appContext, args []string) error {
+	to, err := storage.ParseKind(m.to)
+	if err != nil {
+		return err
+	}
+
+	if to == ctx.kind {
+		return fmt.Errorf("%s: %w", to, ErrSameStorage)
+	}
+
+	dir := config.DataDirPath()
+	before, err := bookmarkKeys(dir)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, keys := range before {
+		count += len(keys)
+	}
+
+	if ok := output.Confirm(fmt.Sprintf(msgMigrate, count, len(before), ctx.kind, to)); !ok {
+		return nil
+	}
+
+	mg := migration{
+		dir:      dir,
+		backup:   backupPath(dir),
+		settings: config.SettingsFilePath(),
+		to:       to,
+		storer:   storage.New(to, ctx.gitOpts...),
+		before:   before,
+	}
+
+	err = mg.run(args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(msgMigrateDone, count, len(before), to, mg.backup)
+	return nil
+}
+
+// migration moves the label files of dir to storer of kind to,
+// keeping the current content of dir at backup.
+type migration struct {
+	dir      string
+	backup   string
+	settings string
+	to       storage.Kind
+	storer   storage.Storer
+	before   map[string][]string
+}
+
+// run puts the backup back in place if any step fails.
+func (mg migration) run(args []string) (err error) {
+	err = os.Rename(mg.dir, mg.backup)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.RemoveAll(mg.dir), os.Rename(mg.backup, mg.dir))
+		}
+	}()
+
+	err = copyLabels(mg.backup, mg.dir)
+	if err != nil {
+		return err
+	}
+
+	err = mg.storer.Init(args...)
+	if err != nil {
+		return err
+	}
+
+	after, err := bookmarkKeys(mg.dir)
+	if err != nil {
+		return err
+	}
+
+	err = verifyMigration(mg.before, after)
+	if err != nil {
+		return err
+	}
+
+	return setStorageKind(mg.settings, mg.to)
+}
+
+// backupPath returns a path next to dir, named after the current
+// time, that is not taken yet.
+func backupPath(dir string) string {
+	res := dir + ".backup-" + time.Now().Format(backupLayout)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(res); errors.Is(err, os.ErrNotExist) {
+			return res
+		}
+
+		res = fmt.Sprintf("%s.backup-%s-%d", dir, time.Now().Format(backupLayout), i)
+	}
+}
+
+// copyLabels copies the label files from src to a new dst directory
+// leaving out anything specific to a backing storage e.g. .git.
+func copyLabels(src string, dst string) error {
+	names, err := label.List(src, nil)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dst, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(dst, name), content, config.StdFileMode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bookmarkKeys returns the keys of the bookmarks in every label file
+// under dir as used by the storage to tell bookmarks apart.
+func bookmarkKeys(dir string) (map[string][]string, error) {
+	names, err := label.List(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]string, len(names))
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		res[name], err = storage.Keys(name, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// verifyMigration checks that every bookmark in before is in the same label
+// after. Labels may hold more bookmarks after, e.g. merged from a remote.
+func verifyMigration(before, after map[string][]string) error {
+	var err error
+	for name, keys := range before {
+		found := map[string]int{}
+		for _, key := range after[name] {
+			found[key]++
+		}
+
+		for _, key := range keys {
+			if found[key] == 0 {
+				err = errors.Join(err, fmt.Errorf("%s: %s: %w", name, key, ErrMigrationMismatch))
+				continue
+			}
+
+			found[key]--
+		}
+	}
+
+	return err
+}
+
+// setStorageKind sets the storage key of the config file at path to kind,
+// leaving the rest of the file, including a comment on the same line, as it is.
+func setStorageKind(path string, kind storage.Kind) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	setting := config.StdStorageKey + ": " + kind.String()
+	if storageKeyRegexp.Match(content) {
+		content = storageKeyRegexp.ReplaceAll(content, []byte(setting+"${1}"))
+	} else {
+		content = append([]byte(setting+"\n"), content...)
+	}
+
+	return os.WriteFile(path, content, config.StdFileMode)
+}
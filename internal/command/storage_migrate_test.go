@@ -0,0 +1,365 @@
+package command
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/google/go-cmp/cmp"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/storage"
+)
+
+const (
+	lineA      = `"A" "https://example.com/a" "" "01950975-fa76-7afc-b1e2-16255225c5d0"` + "\n"
+	lineB      = `"B" "https://example.com/b" "" "01950975-fa76-7afc-b1e2-16255225c5d1"` + "\n"
+	lineLegacy = `"C" "https://example.com/c" ""` + "\n"
+)
+
+var errInit = errors.New("init failed")
+
+type failingStorer struct{}
+
+func (failingStorer) Init(...string) error { return errInit }
+func (failingStorer) Store(string) error   { return nil }
+
+// setupData points the data directory to a temporary one holding files.
+// Tests using it cannot run in parallel since the xdg paths are global.
+func setupData(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dataHome := xdg.DataHome
+	xdg.DataHome = t.TempDir()
+	t.Cleanup(func() {
+		xdg.DataHome = dataHome
+	})
+
+	dir := config.DataDirPath()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestBookmarkKeys(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"root":       lineA + "\n" + lineLegacy,
+		"go":         lineB,
+		".gitignore": "*.tmp\n",
+	}
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+	}
+
+	got, err := bookmarkKeys(dir)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	want := map[string][]string{
+		"root": {"01950975-fa76-7afc-b1e2-16255225c5d0", "https://example.com/c"},
+		"go":   {"01950975-fa76-7afc-b1e2-16255225c5d1"},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestVerifyMigration(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		before map[string][]string
+		after  map[string][]string
+		err    error
+	}{
+		{
+			name:   "same",
+			before: map[string][]string{"root": {"a", "b"}},
+			after:  map[string][]string{"root": {"b", "a"}},
+		},
+		{
+			name:   "more after",
+			before: map[string][]string{"root": {"a"}},
+			after:  map[string][]string{"root": {"a", "b"}, "go": {"c"}},
+		},
+		{
+			name:   "missing bookmark",
+			before: map[string][]string{"root": {"a", "b"}},
+			after:  map[string][]string{"root": {"a"}},
+			err:    ErrMigrationMismatch,
+		},
+		{
+			name:   "moved to another label",
+			before: map[string][]string{"root": {"a"}},
+			after:  map[string][]string{"go": {"a"}},
+			err:    ErrMigrationMismatch,
+		},
+		{
+			name:   "duplicate counted once",
+			before: map[string][]string{"root": {"a", "a"}},
+			after:  map[string][]string{"root": {"a"}},
+			err:    ErrMigrationMismatch,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyMigration(tc.before, tc.after)
+			if !errors.Is(err, tc.err) || tc.err == nil && err != nil {
+				t.Errorf("unexpected error; want %q, got %q", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSetStorageKind(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "replace",
+			content: "sync: auto\nstorage: local\n",
+			want:    "sync: auto\nstorage: git\n",
+		},
+		{
+			name:    "keep trailing comment",
+			content: "storage: local # plain files\nsync: auto\n",
+			want:    "storage: git # plain files\nsync: auto\n",
+		},
+		{
+			name:    "leave other keys",
+			content: "git:\n  storage-branch: main\n",
+			want:    "storage: git\ngit:\n  storage-branch: main\n",
+		},
+		{
+			name: "missing file",
+			want: "storage: git\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tc.content != "" {
+				err := os.WriteFile(path, []byte(tc.content), 0o644)
+				if err != nil {
+					t.Fatalf("unexpected error; got %q", err)
+				}
+			}
+
+			err := setStorageKind(path, storage.Git)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			if diff := cmp.Diff(string(got), tc.want); diff != "" {
+				t.Errorf("output not matching; (-got, +want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func TestCopyLabels(t *testing.T) {
+	t.Parallel()
+
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "data")
+	files := map[string]string{
+		"root":        lineA,
+		"go.generics": lineB,
+		".gitignore":  "*.tmp\n",
+		".git/HEAD":   "ref: refs/heads/main\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+	}
+
+	err := copyLabels(src, dst)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+
+	if diff := cmp.Diff(got, []string{"go.generics", "root"}); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestMigration(t *testing.T) {
+	files := map[string]string{"root": lineA + lineLegacy, "go": lineB}
+	dir := setupData(t, files)
+	settings := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(settings, []byte("storage: local # plain files\n"), 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	before, err := bookmarkKeys(dir)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	toGit := migration{
+		dir:      dir,
+		backup:   backupPath(dir),
+		settings: settings,
+		to:       storage.Git,
+		storer:   storage.New(storage.Git, storage.WithAuthorName("anchor"), storage.WithAuthorEmail("anchor@example.com")),
+		before:   before,
+	}
+
+	err = toGit.run(nil)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, ".git"))
+	if err != nil {
+		t.Errorf("missing git repository in %q; got %q", dir, err)
+	}
+
+	assertMigrated(t, toGit, "storage: git # plain files\n")
+
+	toLocal := toGit
+	toLocal.backup = backupPath(dir)
+	toLocal.to = storage.Local
+	toLocal.storer = storage.New(storage.Local)
+
+	err = toLocal.run(nil)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, ".git"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("unexpected git repository in %q; got %q", dir, err)
+	}
+
+	assertMigrated(t, toLocal, "storage: local # plain files\n")
+}
+
+func TestMigrationRollback(t *testing.T) {
+	files := map[string]string{"root": lineA, "go": lineB}
+	dir := setupData(t, files)
+	settings := filepath.Join(t.TempDir(), "config.yaml")
+
+	before, err := bookmarkKeys(dir)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	mg := migration{
+		dir:      dir,
+		backup:   backupPath(dir),
+		settings: settings,
+		to:       storage.Git,
+		storer:   failingStorer{},
+		before:   before,
+	}
+
+	err = mg.run(nil)
+	if !errors.Is(err, errInit) {
+		t.Errorf("unexpected error; want %q, got %q", errInit, err)
+	}
+
+	_, err = os.Stat(mg.backup)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("backup %q not moved back; got %q", mg.backup, err)
+	}
+
+	_, err = os.Stat(settings)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("unexpected settings file; got %q", err)
+	}
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+
+		if string(got) != want {
+			t.Errorf("%s: want %q, got %q", name, want, got)
+		}
+	}
+}
+
+// assertMigrated checks that the data directory and its backup hold
+// the bookmarks of mg and that the settings file is set to its kind.
+func assertMigrated(t *testing.T, mg migration, settings string) {
+	t.Helper()
+
+	for _, dir := range []string{mg.dir, mg.backup} {
+		got, err := bookmarkKeys(dir)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+
+		if diff := cmp.Diff(got, mg.before); diff != "" {
+			t.Errorf("output not matching; (-got, +want):\n %s", diff)
+		}
+	}
+
+	got, err := os.ReadFile(mg.settings)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if string(got) != settings {
+		t.Errorf("unexpected settings; want %q, got %q", settings, got)
+	}
+}
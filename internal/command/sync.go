@@ -2,11 +2,16 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/loghinalexandru/anchor/internal/config"
 	"github.com/loghinalexandru/anchor/internal/output"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/loghinalexandru/anchor/internal/storage"
 	"github.com/peterbourgon/ff/v4"
 )
 
@@ -16,8 +21,20 @@ const (
 	syncShortHelp = "synchronize changes with configured backing storage"
 	syncLongHelp  = `  In order to persist changes in case of a remote backing storage
   this command needs to be invoked. Otherwise this will persist only on the local file system.
-  This should be performed only for the write part since for reading anchor always gets the
-  latest changes from the configured storage.
+  This should be performed only for the write part since, unless the sync mode is "manual",
+  the remote is pulled before commands at most once per "fetch-interval", in the background
+  for commands that only read bookmarks.
+
+  Local changes are committed first, then the changes on the remote are fetched and merged
+  before pushing. If the remote changes again in the meantime and rejects the push, the
+  fetch and merge are retried. Commits left unpushed by a previous failed sync are pushed
+  as well.
+
+  With the "auto" sync mode changes are pushed right after the command making them, so
+  this is only needed to retry a failed push. With "pull-only" nothing is ever pushed.
+
+  Before asking for confirmation the pending changes are shown bookmark by bookmark and
+  grouped by label. Use --stat to only show how many bookmarks changed in each label.
 
   Has no effect if the backing storage is set to "local".
 `
@@ -26,19 +43,36 @@ const (
 const (
 	msgNothingToSync    = "Nothing to sync, there are no local changes."
 	msgSyncConfirmation = "Sync changes with remote?"
+	msgUnpushed         = "%d local commits not pushed yet\n"
+	msgUpToDate         = "Already up to date"
+	msgPullOnly         = "Sync mode is pull-only, local changes are not pushed."
+)
+
+var (
+	ErrOffline = errors.New("cannot sync while offline")
 )
 
+// maxDescribedLabels limits the labels named in generated commit messages.
+const maxDescribedLabels = 3
+
 type Differ interface {
-	Diff() (string, error)
+	Diff() ([]storage.Change, error)
+}
+
+type Syncer interface {
+	Sync(msg string) (storage.Summary, error)
+	Unpushed() (int, error)
 }
 
 type syncCmd struct {
-	msg string
+	msg  string
+	stat bool
 }
 
 func (sync *syncCmd) manifest(parent *ff.FlagSet) *ff.Command {
 	flags := ff.NewFlagSet("sync").SetParent(parent)
 	flags.StringVar(&sync.msg, 'm', "message", config.StdSyncMsg, "Optional sync message")
+	flags.BoolVar(&sync.stat, 0, "stat", "show only the number of changed bookmarks per label")
 
 	return &ff.Command{
 		Name:      syncName,
@@ -53,23 +87,219 @@ func (sync *syncCmd) manifest(parent *ff.FlagSet) *ff.Command {
 }
 
 func (sync *syncCmd) handle(ctx appContext, _ []string) error {
+	if ctx.offline {
+		return ErrOffline
+	}
+
+	// Pulling already happened before the command ran.
+	if ctx.syncMode == storage.PullOnlySync {
+		fmt.Println(msgPullOnly)
+		return nil
+	}
+
+	syncer, canSync := ctx.storer.(Syncer)
 	if d, ok := ctx.storer.(Differ); ok {
-		status, err := d.Diff()
+		changes, err := d.Diff()
 		if err != nil {
 			return err
 		}
 
-		if status == "" {
+		var pending int
+		if canSync {
+			pending, err = syncer.Unpushed()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(changes) == 0 && pending == 0 {
 			fmt.Println(msgNothingToSync)
 			return nil
 		}
 
-		_, _ = fmt.Fprint(os.Stdout, status)
+		if sync.stat {
+			printStat(changes)
+		} else {
+			printDiff(changes)
+		}
+
+		if pending > 0 {
+			fmt.Printf(msgUnpushed, pending)
+		}
 	}
 
 	if ok := output.Confirm(msgSyncConfirmation); !ok {
 		return nil
 	}
 
-	return ctx.storer.Store(sync.msg)
+	if !canSync {
+		return ctx.storer.Store(sync.msg)
+	}
+
+	summary, err := syncer.Sync(sync.msg)
+	printSummary(summary)
+	if err == nil && summary == (storage.Summary{}) {
+		fmt.Println(msgUpToDate)
+	}
+
+	return err
+}
+
+func printSummary(s storage.Summary) {
+	if s.Committed {
+		fmt.Println("Committed local changes")
+	}
+
+	if s.Pulled > 0 {
+		fmt.Printf("Pulled %d commits\n", s.Pulled)
+	}
+
+	if s.Pushed > 0 {
+		fmt.Printf("Pushed %d commits\n", s.Pushed)
+	}
+}
+
+// printDiff prints changes grouped by label, showing for
+// modified bookmarks every field that changed.
+func printDiff(changes []storage.Change) {
+	var current string
+	for _, c := range changes {
+		if c.Label != current {
+			current = c.Label
+			fmt.Println(style.Heading().Render(current))
+		}
+
+		switch c.Kind {
+		case storage.Added:
+			fmt.Println(style.Inserted().Render("  + "+c.New.Title()) + " " + style.Muted().Render(c.New.URL()))
+		case storage.Removed:
+			fmt.Println(style.Deleted().Render("  - "+c.Old.Title()) + " " + style.Muted().Render(c.Old.URL()))
+		case storage.Reordered:
+			fmt.Println(style.Muted().Render("  ~ bookmarks reordered"))
+		case storage.Touched:
+			fmt.Println(style.Muted().Render("  ~ file changed"))
+		case storage.Modified:
+			fmt.Println("  ~ " + c.New.Title())
+			printField("title", c.Old.Title(), c.New.Title())
+			printField("url", c.Old.URL(), c.New.URL())
+			printField("comment", c.Old.Comment(), c.New.Comment())
+		}
+	}
+}
+
+func printField(name string, old string, cur string) {
+	if old == cur {
+		return
+	}
+
+	fmt.Printf("    %s: %s → %s\n", name, style.Deleted().Render(strconv.Quote(old)), style.Inserted().Render(strconv.Quote(cur)))
+}
+
+// printStat prints the number of bookmarks added, removed and
+// modified in each label followed by the totals.
+func printStat(changes []storage.Change) {
+	var labels []string
+	counts := map[string]map[storage.ChangeKind]int{}
+	total := map[storage.ChangeKind]int{}
+	for _, c := range changes {
+		if counts[c.Label] == nil {
+			labels = append(labels, c.Label)
+			counts[c.Label] = map[storage.ChangeKind]int{}
+		}
+
+		counts[c.Label][c.Kind]++
+		total[c.Kind]++
+	}
+
+	var width int
+	for _, l := range labels {
+		width = max(width, len(l))
+	}
+
+	for _, l := range labels {
+		n := counts[l]
+		line := fmt.Sprintf("%-*s  %s %s ~%d", width, l,
+			style.Inserted().Render(fmt.Sprintf("+%d", n[storage.Added])),
+			style.Deleted().Render(fmt.Sprintf("-%d", n[storage.Removed])),
+			n[storage.Modified])
+
+		if n[storage.Reordered] > 0 {
+			line += style.Muted().Render(" reordered")
+		}
+
+		if n[storage.Touched] > 0 {
+			line += style.Muted().Render(" file changed")
+		}
+
+		fmt.Println(line)
+	}
+
+	fmt.Printf("%d added, %d removed, %d changed in %d labels\n",
+		total[storage.Added], total[storage.Removed], total[storage.Modified], len(labels))
+}
+
+// describe generates a commit message for changes. A single change names
+// the bookmark, otherwise the changes are counted for the labels touched.
+func describe(changes []storage.Change) string {
+	if len(changes) == 0 {
+		return config.StdSyncMsg
+	}
+
+	if len(changes) == 1 {
+		c := changes[0]
+		switch c.Kind {
+		case storage.Added:
+			return fmt.Sprintf("Add %q to %s", c.New.Title(), c.Label)
+		case storage.Removed:
+			return fmt.Sprintf("Remove %q from %s", c.Old.Title(), c.Label)
+		case storage.Reordered:
+			return fmt.Sprintf("Reorder %s", c.Label)
+		case storage.Touched:
+			return fmt.Sprintf("Update %s", c.Label)
+		}
+
+		if c.Old.Title() != c.New.Title() {
+			return fmt.Sprintf("Rename %q to %q in %s", c.Old.Title(), c.New.Title(), c.Label)
+		}
+
+		return fmt.Sprintf("Edit %q in %s", c.New.Title(), c.Label)
+	}
+
+	var labels []string
+	counts := map[storage.ChangeKind]int{}
+	for _, c := range changes {
+		if !slices.Contains(labels, c.Label) {
+			labels = append(labels, c.Label)
+		}
+
+		counts[c.Kind]++
+	}
+
+	var parts []string
+	for _, k := range []struct {
+		kind storage.ChangeKind
+		verb string
+	}{
+		{storage.Added, "added"},
+		{storage.Removed, "removed"},
+		{storage.Modified, "edited"},
+	} {
+		if counts[k.kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[k.kind], k.verb))
+		}
+	}
+
+	if counts[storage.Reordered] > 0 {
+		parts = append(parts, "reordered")
+	}
+
+	if counts[storage.Touched] > 0 {
+		parts = append(parts, "files changed")
+	}
+
+	if len(labels) > maxDescribedLabels {
+		labels = append(labels[:maxDescribedLabels], fmt.Sprintf("%d more", len(labels)-maxDescribedLabels))
+	}
+
+	return fmt.Sprintf("Update %s: %s", strings.Join(labels, ", "), strings.Join(parts, ", "))
 }
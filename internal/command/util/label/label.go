@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/archive"
 	"github.com/loghinalexandru/anchor/internal/config"
 	"github.com/loghinalexandru/anchor/internal/model"
 	"github.com/sahilm/fuzzy"
@@ -20,6 +22,11 @@ var (
 	ErrMissingLabel = errors.New("missing file for label(s) passed")
 )
 
+// Index is the search index of archived pages.
+type Index interface {
+	Remove(id uuid.UUID) error
+}
+
 var notLabelRegexp = regexp.MustCompile(`([^a-z0-9-]|^$)`)
 
 // Open validates and opens the file constructed from the labels.
@@ -58,9 +65,10 @@ func OpenFuzzy(rootDir string, labels []string, flag int) (*os.File, error) {
 	return fh, err
 }
 
-// Remove validates and removes the file constructed from the labels.
+// Remove validates and removes the file constructed from the labels
+// together with the archives of its bookmarks and their documents in index.
 // If the file does not exist, has no effect.
-func Remove(rootDir string, labels []string) error {
+func Remove(rootDir string, labels []string, index Index) error {
 	err := validate(labels)
 	if err != nil {
 		return err
@@ -74,7 +82,8 @@ func Remove(rootDir string, labels []string) error {
 	scanner := bufio.NewScanner(fh)
 	for scanner.Scan() {
 		bk, _ := model.BookmarkLine(scanner.Text())
-		_ = os.Remove(config.ArchiveFilePath(bk.Id()))
+		_ = archive.Remove(bk.Id())
+		_ = index.Remove(bk.Id())
 	}
 
 	_ = fh.Close()
@@ -87,6 +96,78 @@ func Remove(rootDir string, labels []string) error {
 	return nil
 }
 
+// List returns the names of the label files under rootDir that are equal to
+// or nested under labels, e.g. "programming" matches both "programming" and
+// "programming.go". If no labels are provided, all label files are returned.
+// Hidden files and directories are skipped.
+func List(rootDir string, labels []string) ([]string, error) {
+	err := validate(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	dd, err := os.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, d := range dd {
+		if !d.IsDir() && matches(d.Name(), labels) {
+			res = append(res, d.Name())
+		}
+	}
+
+	return res, nil
+}
+
+// Matcher returns a func reporting whether a label file name is equal to
+// or nested under labels, the same way List selects files.
+func Matcher(labels []string) (func(name string) bool, error) {
+	err := validate(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(name string) bool {
+		return matches(name, labels)
+	}, nil
+}
+
+// Name validates labels and returns the name of the label file they map to.
+func Name(labels []string) (string, error) {
+	err := validate(labels)
+	if err != nil {
+		return "", err
+	}
+
+	return filename(labels), nil
+}
+
+// Bookmarks parses every bookmark from the label file name under rootDir.
+func Bookmarks(rootDir string, name string) (res []*model.Bookmark, err error) {
+	fh, err := os.Open(filepath.Join(rootDir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err = errors.Join(err, fh.Close())
+	}()
+
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		bk, err := model.BookmarkLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		res = append(res, bk)
+	}
+
+	return res, scanner.Err()
+}
+
 // Format formats and strips out any invalid characters from provided labels.
 // Invalid character is anything that [^a-z0-9-] does match.
 func Format(labels []string) []string {
@@ -121,6 +202,15 @@ func match(rootDir string, labels []string) string {
 	return matches[0].Str
 }
 
+func matches(name string, labels []string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	prefix := strings.Join(labels, config.StdLabelSeparator)
+	return prefix == "" || name == prefix || strings.HasPrefix(name, prefix+config.StdLabelSeparator)
+}
+
 func filename(labels []string) string {
 	if len(labels) == 0 {
 		return config.StdLabel
@@ -2,9 +2,14 @@ package label
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/adrg/xdg"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/config"
 )
 
 func TestName(t *testing.T) {
@@ -107,3 +112,100 @@ func TestValidateBadLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"root", "programming", "programming.go", "programmingx", ".gitignore"} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o600)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+	}
+
+	err := os.Mkdir(filepath.Join(dir, ".git"), os.ModePerm)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	tsc := map[string]struct {
+		labels []string
+		want   []string
+	}{
+		"all-labels": {
+			labels: nil,
+			want:   []string{"programming", "programming.go", "programmingx", "root"},
+		},
+		"parent-label": {
+			labels: []string{"programming"},
+			want:   []string{"programming", "programming.go"},
+		},
+		"nested-label": {
+			labels: []string{"programming", "go"},
+			want:   []string{"programming.go"},
+		},
+		"missing-label": {
+			labels: []string{"rust"},
+			want:   nil,
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			got, err := List(dir, c.labels)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			if !cmp.Equal(c.want, got) {
+				t.Error(cmp.Diff(c.want, got))
+			}
+		})
+	}
+}
+
+type index []uuid.UUID
+
+func (idx *index) Remove(id uuid.UUID) error {
+	*idx = append(*idx, id)
+	return nil
+}
+
+func TestRemove(t *testing.T) {
+	dataHome := xdg.DataHome
+	xdg.DataHome = t.TempDir()
+	t.Cleanup(func() {
+		xdg.DataHome = dataHome
+	})
+
+	id := uuid.MustParse("01950975-fa76-7afc-b1e2-16255225c5d0")
+	err := os.MkdirAll(config.SnapshotDirPath(id), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "go"), []byte(`"Go" "https://go.dev" "" "`+id.String()+`"`+"\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var idx index
+	err = Remove(dir, []string{"go"}, &idx)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "go")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("label file not removed; got %q", err)
+	}
+
+	if _, err := os.Stat(config.SnapshotDirPath(id)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("archive not removed; got %q", err)
+	}
+
+	if diff := cmp.Diff([]uuid.UUID(idx), []uuid.UUID{id}); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
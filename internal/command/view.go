@@ -4,11 +4,12 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/loghinalexandru/anchor/internal/archive"
 	"github.com/loghinalexandru/anchor/internal/command/util/label"
 	"github.com/loghinalexandru/anchor/internal/config"
 	"github.com/loghinalexandru/anchor/internal/model"
@@ -65,7 +66,7 @@ func (v *viewCmd) handle(ctx appContext, args []string) (err error) {
 		err = errors.Join(err, fh.Close())
 	}()
 
-	var bookmarks []list.Item
+	var bookmarks []*model.Bookmark
 	scanner := bufio.NewScanner(fh)
 	for scanner.Scan() {
 		bk, err := model.BookmarkLine(scanner.Text())
@@ -76,13 +77,35 @@ func (v *viewCmd) handle(ctx appContext, args []string) (err error) {
 		bookmarks = append(bookmarks, bk)
 	}
 
-	runner := tea.NewProgram(bubbletea.NewView(bookmarks, filepath.Base(fh.Name())), tea.WithContext(ctx))
+	history, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(fh.Name())
+	mode, ok := ctx.labelSort[name]
+	if !ok {
+		mode = ctx.sortMode
+	}
+
+	runner := tea.NewProgram(bubbletea.NewView(
+		bookmarks,
+		name,
+		bubbletea.WithSortMode(mode),
+		bubbletea.WithHistory(history),
+		bubbletea.WithKeyMap(ctx.keys),
+		bubbletea.WithOpener(ctx.opener)), tea.WithContext(ctx))
 	state, err := runner.Run()
 	if err != nil {
 		return err
 	}
 
 	view := state.(*bubbletea.View)
+	err = writeHistory(view.History())
+	if err != nil {
+		return err
+	}
+
 	if !view.Dirty() {
 		return nil
 	}
@@ -116,9 +139,42 @@ func (v *viewCmd) handle(ctx appContext, args []string) (err error) {
 		switch a.Operation {
 		case bubbletea.Delete:
 			// Explicitly ignore if there is a remove error.
-			_ = os.Remove(config.ArchiveFilePath(a.Target))
+			_ = archive.Remove(a.Target)
+			_ = ctx.index.Remove(a.Target)
 		}
 	}
 
 	return nil
 }
+
+func readHistory() (model.History, error) {
+	fh, err := os.Open(config.HistoryFilePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return model.History{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer fh.Close()
+	return model.ReadHistory(fh)
+}
+
+func writeHistory(history model.History) (err error) {
+	err = os.MkdirAll(config.StateDirPath(), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	fh, err := os.Create(config.HistoryFilePath())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = errors.Join(err, fh.Close())
+	}()
+
+	return history.Write(fh)
+}
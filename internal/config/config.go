@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/adrg/xdg"
@@ -13,7 +14,18 @@ const (
 	StdDirName        = "anchor"
 	StdStorageKey     = "storage"
 	StdSyncModeKey    = "sync"
+	StdSortKey        = "sort"
+	StdLabelsKey      = "labels"
+	StdKeysKey        = "keys"
+	StdThemeKey       = "theme"
+	StdThemesKey      = "themes"
+	StdOpenerKey      = "opener"
+	StdArchiveKey     = "archive"
+	StdGitKey         = "git"
+	StdFetchKey       = "fetch-interval"
+	StdFetchInterval  = 5 * time.Minute
 	StdHttpTimeout    = 3 * time.Second
+	StdArchiveTimeout = 30 * time.Second
 	StdSyncMsg        = "Sync bookmarks"
 	StdFileMode       = os.FileMode(0o666)
 	StdLabel          = "root"
@@ -29,6 +41,11 @@ func SettingsFilePath() string {
 	return config
 }
 
+// TemplateFilePath is the user provided template for archived pages.
+func TemplateFilePath() string {
+	return filepath.Join(xdg.ConfigHome, StdDirName, "archive.html")
+}
+
 func DataDirPath() string {
 	return filepath.Join(xdg.DataHome, StdDirName, "data")
 }
@@ -37,6 +54,47 @@ func ArchiveDirPath() string {
 	return filepath.Join(xdg.DataHome, StdDirName, "archive")
 }
 
+// ArchiveFilePath is the single archive of a bookmark stored before
+// snapshots were introduced. It is still read as the oldest snapshot.
 func ArchiveFilePath(id uuid.UUID) string {
 	return filepath.Join(ArchiveDirPath(), id.String()+".html")
 }
+
+// SnapshotDirPath holds the timestamped archives of a bookmark.
+func SnapshotDirPath(id uuid.UUID) string {
+	return filepath.Join(ArchiveDirPath(), id.String())
+}
+
+// IndexFilePath holds the full-text search index of the archives.
+func IndexFilePath() string {
+	return filepath.Join(xdg.DataHome, StdDirName, "index")
+}
+
+func StateDirPath() string {
+	return filepath.Join(xdg.StateHome, StdDirName)
+}
+
+func HistoryFilePath() string {
+	return filepath.Join(StateDirPath(), "history")
+}
+
+// FetchFilePath holds the time of the last successful fetch from the remote.
+func FetchFilePath() string {
+	return filepath.Join(StateDirPath(), "last-fetch")
+}
+
+// FetchAttemptFilePath holds the time the last background fetch was started.
+func FetchAttemptFilePath() string {
+	return filepath.Join(StateDirPath(), "fetch-attempt")
+}
+
+// LabelSetting extracts the label from a per-label config
+// key in the form of "labels.<label>.<setting>".
+func LabelSetting(key, setting string) (string, bool) {
+	label, ok := strings.CutPrefix(key, StdLabelsKey+".")
+	if !ok {
+		return "", false
+	}
+
+	return strings.CutSuffix(label, "."+setting)
+}
@@ -0,0 +1,440 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	mimetype = "application/epub+zip"
+	xhtmlExt = ".xhtml"
+	imageDir = "images"
+)
+
+var (
+	ErrNoChapters = errors.New("book has no chapters")
+)
+
+// coreTypes are the image media types every EPUB 3
+// reading system supports, keyed by file extension.
+var coreTypes = map[string]string{
+	".gif":  "image/gif",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+// Book is an EPUB 3 publication with one chapter per archived page.
+type Book struct {
+	// Id uniquely identifies the publication e.g. "urn:uuid:...".
+	Id       string
+	Title    string
+	Language string
+	Modified time.Time
+	Chapters []Chapter
+}
+
+// Chapter is a single page of the book.
+type Chapter struct {
+	Title string
+	// URL links the chapter back to the original page, if set.
+	URL string
+	// Content is the HTML body of the chapter.
+	Content string
+	// Assets resolves the relative image paths of Content. Images that
+	// are not found in it or reference remote URLs are left out.
+	Assets fs.FS
+}
+
+type item struct {
+	Id         string
+	Href       string
+	MediaType  string
+	Properties string
+}
+
+type navEntry struct {
+	Href  string
+	Title string
+}
+
+type file struct {
+	name string
+	data []byte
+}
+
+// Write packages b as an EPUB file into w.
+func (b Book) Write(w io.Writer) error {
+	if len(b.Chapters) == 0 {
+		return ErrNoChapters
+	}
+
+	lang := b.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	var files []file
+	var items []item
+	var nav []navEntry
+	for i, ch := range b.Chapters {
+		name := fmt.Sprintf("chapter-%03d%s", i+1, xhtmlExt)
+		body, images, svg, err := ch.xhtml(fmt.Sprintf("chapter-%03d", i+1))
+		if err != nil {
+			return fmt.Errorf("%q: %w", ch.Title, err)
+		}
+
+		page, err := execute(chapterTmpl, map[string]any{
+			"Lang":  lang,
+			"Title": ch.Title,
+			"URL":   ch.URL,
+			"Body":  body,
+		})
+		if err != nil {
+			return err
+		}
+
+		var props string
+		if svg {
+			props = "svg"
+		}
+
+		files = append(files, file{name: name, data: page})
+		items = append(items, item{
+			Id:         strings.TrimSuffix(name, xhtmlExt),
+			Href:       name,
+			MediaType:  "application/xhtml+xml",
+			Properties: props,
+		})
+		nav = append(nav, navEntry{Href: name, Title: ch.Title})
+
+		for _, img := range images {
+			files = append(files, img)
+			items = append(items, item{
+				Id:        "img-" + strings.NewReplacer("/", "-", ".", "-").Replace(img.name),
+				Href:      img.name,
+				MediaType: coreTypes[strings.ToLower(path.Ext(img.name))],
+			})
+		}
+	}
+
+	navPage, err := execute(navTmpl, map[string]any{
+		"Lang":    lang,
+		"Title":   b.Title,
+		"Entries": nav,
+	})
+	if err != nil {
+		return err
+	}
+
+	opf, err := execute(packageTmpl, map[string]any{
+		"Id":       b.Id,
+		"Title":    b.Title,
+		"Lang":     lang,
+		"Modified": b.Modified.UTC().Format(time.RFC3339),
+		"Items":    items,
+	})
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype must be the first entry and stored
+	// uncompressed so readers can identify the file.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: b.Modified})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(mw, mimetype)
+	if err != nil {
+		return err
+	}
+
+	files = append([]file{
+		{name: "content.opf", data: opf},
+		{name: "nav.xhtml", data: navPage},
+	}, files...)
+
+	err = create(zw, "META-INF/container.xml", []byte(container), b.Modified)
+	for _, f := range files {
+		if err != nil {
+			break
+		}
+
+		err = create(zw, path.Join("OEBPS", f.name), f.data, b.Modified)
+	}
+
+	return errors.Join(err, zw.Close())
+}
+
+func create(zw *zip.Writer, name string, data []byte, modified time.Time) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+
+	_, err = fw.Write(data)
+	return err
+}
+
+// xhtml converts the content of ch to XHTML and collects the images it
+// references. Anything a reading system would not render or an EPUB
+// checker would reject e.g. scripts, styles or remote images is dropped.
+func (ch Chapter) xhtml(prefix string) (string, []file, bool, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(ch.Content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	c := &converter{assets: ch.Assets, prefix: prefix, done: map[string]string{}}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		if !c.keep(n) {
+			continue
+		}
+
+		c.walk(n)
+		err = html.Render(&buf, n)
+		if err != nil {
+			return "", nil, false, err
+		}
+	}
+
+	return buf.String(), c.images, c.svg, nil
+}
+
+type converter struct {
+	assets fs.FS
+	prefix string
+	images []file
+	done   map[string]string
+	svg    bool
+}
+
+// keep reports whether n belongs in a chapter.
+func (c *converter) keep(n *html.Node) bool {
+	if n.Type == html.CommentNode || n.Type == html.DoctypeNode {
+		return false
+	}
+
+	if n.Type != html.ElementNode {
+		return true
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Noscript, atom.Style, atom.Link, atom.Meta, atom.Title,
+		atom.Iframe, atom.Object, atom.Embed, atom.Form, atom.Input, atom.Button:
+		return false
+	case atom.Img, atom.Source:
+		return c.image(n)
+	case atom.Svg:
+		c.svg = true
+	}
+
+	return true
+}
+
+func (c *converter) walk(n *html.Node) {
+	if n.Type == html.ElementNode {
+		n.Attr = attributes(n.Attr)
+	}
+
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if !c.keep(child) {
+			n.RemoveChild(child)
+		} else {
+			c.walk(child)
+		}
+
+		child = next
+	}
+}
+
+// image points the src of n to a packaged copy of the image
+// and reports false if the image cannot be packaged.
+func (c *converter) image(n *html.Node) bool {
+	for i, attr := range n.Attr {
+		if attr.Key != "src" {
+			continue
+		}
+
+		if strings.HasPrefix(attr.Val, "data:image/") {
+			return true
+		}
+
+		if href, ok := c.done[attr.Val]; ok {
+			n.Attr[i].Val = href
+			return true
+		}
+
+		href, ok := c.include(attr.Val)
+		if !ok {
+			return false
+		}
+
+		c.done[attr.Val] = href
+		n.Attr[i].Val = href
+		return true
+	}
+
+	return false
+}
+
+func (c *converter) include(src string) (string, bool) {
+	if c.assets == nil || strings.Contains(src, ":") {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(path.Clean(src), "/")
+	if _, ok := coreTypes[strings.ToLower(path.Ext(name))]; !ok || !fs.ValidPath(name) {
+		return "", false
+	}
+
+	data, err := fs.ReadFile(c.assets, name)
+	if err != nil {
+		return "", false
+	}
+
+	href := path.Join(imageDir, fmt.Sprintf("%s-%d%s", c.prefix, len(c.images)+1, strings.ToLower(path.Ext(name))))
+	c.images = append(c.images, file{name: href, data: data})
+
+	return href, true
+}
+
+// attributes drops the attributes that are not well-formed XML names
+// or not allowed in EPUB content documents.
+func attributes(attrs []html.Attribute) []html.Attribute {
+	res := attrs[:0]
+	for _, a := range attrs {
+		if a.Namespace != "" || !validName(a.Key) || strings.HasPrefix(a.Key, "on") {
+			continue
+		}
+
+		switch a.Key {
+		case "srcset", "sizes", "loading", "style", "xmlns":
+			continue
+		}
+
+		res = append(res, a)
+	}
+
+	return res
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func execute(tmpl *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	return buf.Bytes(), err
+}
+
+func escape(s string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+var funcs = template.FuncMap{"xml": escape}
+
+const container = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var packageTmpl = template.Must(template.New("package").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{xml .Lang}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{xml .Id}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:language>{{xml .Lang}}</dc:language>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+{{- range .Items}}
+    <item id="{{xml .Id}}" href="{{xml .Href}}" media-type="{{.MediaType}}"{{if .Properties}} properties="{{.Properties}}"{{end}}/>
+{{- end}}
+  </manifest>
+  <spine>
+{{- range .Items}}{{if eq .MediaType "application/xhtml+xml"}}
+    <itemref idref="{{xml .Id}}"/>
+{{- end}}{{end}}
+  </spine>
+</package>
+`))
+
+var navTmpl = template.Must(template.New("nav").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{xml .Lang}}" lang="{{xml .Lang}}">
+<head>
+  <title>{{xml .Title}}</title>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>{{xml .Title}}</h1>
+    <ol>
+{{- range .Entries}}
+      <li><a href="{{xml .Href}}">{{xml .Title}}</a></li>
+{{- end}}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var chapterTmpl = template.Must(template.New("chapter").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{xml .Lang}}" lang="{{xml .Lang}}">
+<head>
+  <title>{{xml .Title}}</title>
+</head>
+<body>
+  <h1>{{xml .Title}}</h1>
+{{- if .URL}}
+  <p><a href="{{xml .URL}}">{{xml .URL}}</a></p>
+{{- end}}
+  <section>{{.Body}}</section>
+</body>
+</html>
+`))
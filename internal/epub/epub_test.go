@@ -0,0 +1,149 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	book := Book{
+		Id:       "urn:uuid:0b0a7b4e-6a43-4b59-9d4a-3d1b0c0f0b1a",
+		Title:    "programming & go",
+		Modified: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Chapters: []Chapter{
+			{
+				Title:   "Garbage <collectors>",
+				URL:     "https://example.com/gc?a=1&b=2",
+				Content: `<p>Mark<br>and sweep</p><img src="snap_files/a.png" alt="a"><img src="https://example.com/b.png"><script>alert(1)</script>`,
+				Assets:  fstest.MapFS{"snap_files/a.png": {Data: []byte("png")}},
+			},
+			{
+				Title:   "Generics",
+				Content: `<div onclick="x()" style="color:red"><p>Type parameters</p></div>`,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := book.Write(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	var names []string
+	files := map[string]string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = read(t, f)
+	}
+
+	want := []string{
+		"mimetype",
+		"META-INF/container.xml",
+		"OEBPS/content.opf",
+		"OEBPS/nav.xhtml",
+		"OEBPS/chapter-001.xhtml",
+		"OEBPS/images/chapter-001-1.png",
+		"OEBPS/chapter-002.xhtml",
+	}
+	if diff := cmp.Diff(names, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+
+	if zr.File[0].Method != zip.Store || files["mimetype"] != mimetype {
+		t.Errorf("mimetype not stored first; got method %d, %q", zr.File[0].Method, files["mimetype"])
+	}
+
+	for name, content := range files {
+		if strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".opf") || strings.HasSuffix(name, ".xhtml") {
+			wellFormed(t, name, content)
+		}
+	}
+
+	for _, s := range []string{
+		`<dc:title>programming &amp; go</dc:title>`,
+		`<meta property="dcterms:modified">2026-10-18T12:00:00Z</meta>`,
+		`<item id="img-images-chapter-001-1-png" href="images/chapter-001-1.png" media-type="image/png"/>`,
+		`<itemref idref="chapter-002"/>`,
+	} {
+		if !strings.Contains(files["OEBPS/content.opf"], s) {
+			t.Errorf("content.opf does not contain %q", s)
+		}
+	}
+
+	if !strings.Contains(files["OEBPS/nav.xhtml"], `<a href="chapter-001.xhtml">Garbage &lt;collectors&gt;</a>`) {
+		t.Errorf("nav.xhtml does not contain chapter entry; got %s", files["OEBPS/nav.xhtml"])
+	}
+
+	chapter := files["OEBPS/chapter-001.xhtml"]
+	for _, s := range []string{"example.com/b.png", "<script", "snap_files"} {
+		if strings.Contains(chapter, s) {
+			t.Errorf("chapter-001.xhtml contains %q; got %s", s, chapter)
+		}
+	}
+
+	if strings.Contains(files["OEBPS/chapter-002.xhtml"], "onclick") || strings.Contains(files["OEBPS/chapter-002.xhtml"], "style=") {
+		t.Errorf("chapter-002.xhtml kept attributes; got %s", files["OEBPS/chapter-002.xhtml"])
+	}
+}
+
+func TestWriteNoChapters(t *testing.T) {
+	t.Parallel()
+
+	err := Book{Title: "empty"}.Write(io.Discard)
+	if !errors.Is(err, ErrNoChapters) {
+		t.Errorf("unexpected error; want %q, got %q", ErrNoChapters, err)
+	}
+}
+
+func read(t *testing.T, f *zip.File) string {
+	t.Helper()
+
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	return string(data)
+}
+
+func wellFormed(t *testing.T, name string, content string) {
+	t.Helper()
+
+	dec := xml.NewDecoder(strings.NewReader(content))
+	dec.Strict = true
+	for {
+		_, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			t.Errorf("%s is not well-formed; got %q\n%s", name, err, content)
+			return
+		}
+	}
+}
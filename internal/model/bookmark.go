@@ -10,10 +10,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// FilterSeparator delimits the fields returned by FilterValue.
+const FilterSeparator = "\x1f"
+
 var (
 	ErrDuplicateBookmark = errors.New("duplicate bookmark line")
 	ErrInvalidBookmark   = errors.New("cannot parse bookmark: arguments mismatch")
@@ -157,12 +161,23 @@ func (b *Bookmark) String() string {
 }
 
 func (b *Bookmark) Write(rw io.ReadWriteSeeker) error {
-	_, err := rw.Seek(0, io.SeekStart)
+	err := b.Check(rw)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(rw, b.String())
+	return err
+}
+
+// Check returns ErrDuplicateBookmark if the URL is already present in rs.
+func (b *Bookmark) Check(rs io.ReadSeeker) error {
+	_, err := rs.Seek(0, io.SeekStart)
 	if err != nil {
 		return err
 	}
 
-	content, err := io.ReadAll(rw)
+	content, err := io.ReadAll(rs)
 	if err != nil {
 		return err
 	}
@@ -172,8 +187,7 @@ func (b *Bookmark) Write(rw io.ReadWriteSeeker) error {
 		return fmt.Errorf("%s: %w", b.url, ErrDuplicateBookmark)
 	}
 
-	_, err = fmt.Fprint(rw, b.String())
-	return err
+	return nil
 }
 
 func (b *Bookmark) Update(title string) {
@@ -196,10 +210,38 @@ func (b *Bookmark) URL() string {
 	return b.url
 }
 
+func (b *Bookmark) Comment() string {
+	return b.comment
+}
+
+// Domain returns the host of the bookmark URL without
+// the "www." prefix or an empty string if it cannot be parsed.
+func (b *Bookmark) Domain() string {
+	u, err := url.Parse(b.url)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// Added returns the creation time embedded in the bookmark id.
+// Returns the zero value for ids that do not carry a timestamp.
+func (b *Bookmark) Added() time.Time {
+	switch b.id.Version() {
+	case 1, 6, 7:
+		return time.Unix(b.id.Time().UnixTime())
+	default:
+		return time.Time{}
+	}
+}
+
 func (b *Bookmark) Id() uuid.UUID {
 	return b.id
 }
 
+// FilterValue returns the title, URL, comment and domain
+// of the bookmark in this order joined by FilterSeparator.
 func (b *Bookmark) FilterValue() string {
-	return b.title
+	return strings.Join([]string{b.title, b.url, b.comment, b.Domain()}, FilterSeparator)
 }
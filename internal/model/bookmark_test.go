@@ -2,11 +2,13 @@ package model
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -210,6 +212,37 @@ func TestWrite(t *testing.T) {
 	}
 }
 
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	content := "\"Google\" \"https://google.com\" \"\" \"01950975-fa76-7afc-b1e2-16255225c5d0\"\n"
+	tcs := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{name: "duplicate", url: "https://google.com", want: ErrDuplicateBookmark},
+		{name: "prefix", url: "https://google.co", want: nil},
+		{name: "new", url: "https://go.dev", want: nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bk, err := NewBookmark(tc.url, WithTitle("title"))
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			err = bk.Check(strings.NewReader(content))
+			if !errors.Is(err, tc.want) {
+				t.Errorf("unexpected error; want %q, got %q", tc.want, err)
+			}
+		})
+	}
+}
+
 type roundTripFunc func(req *http.Request) *http.Response
 
 func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// History keeps track of the last time each bookmark was opened.
+// It is stored locally and never synced with the backing storage.
+type History map[uuid.UUID]time.Time
+
+// ReadHistory parses entries in the form of "<id> <RFC3339 time>".
+// Malformed lines are skipped.
+func ReadHistory(r io.Reader) (History, error) {
+	res := History{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		rawID, rawTime, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			continue
+		}
+
+		opened, err := time.Parse(time.RFC3339, rawTime)
+		if err != nil {
+			continue
+		}
+
+		res[id] = opened
+	}
+
+	return res, scanner.Err()
+}
+
+func (h History) Write(w io.Writer) error {
+	for id, opened := range h {
+		_, err := fmt.Fprintf(w, "%s %s\n", id, opened.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h History) Touch(id uuid.UUID) {
+	h[id] = time.Now()
+}
+
+func (h History) LastOpened(id uuid.UUID) time.Time {
+	return h[id]
+}
@@ -0,0 +1,213 @@
+package opener
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var (
+	ErrEmptyCommand  = errors.New("empty opener command")
+	ErrUnclosedQuote = errors.New("unclosed quote in opener command")
+)
+
+// Opener launches URLs with a user configured command picked by
+// domain, falling back to the default handler of the system.
+//
+// Commands are split into arguments like a shell would, honoring quotes,
+// and support the placeholders {url}, {title} and {domain}. If {url} is
+// missing, the URL is appended as the last argument.
+type Opener struct {
+	command  string
+	domains  map[string]string
+	terminal bool
+}
+
+func New(opts ...func(*Opener)) *Opener {
+	res := &Opener{
+		domains: map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// WithCommand sets the command used for every domain without an override.
+func WithCommand(command string) func(*Opener) {
+	return func(o *Opener) {
+		o.command = strings.TrimSpace(command)
+	}
+}
+
+// WithDomain sets the command used for domain and all its sub-domains.
+func WithDomain(domain string, command string) func(*Opener) {
+	return func(o *Opener) {
+		o.domains[strings.TrimPrefix(strings.ToLower(domain), "www.")] = strings.TrimSpace(command)
+	}
+}
+
+// WithTerminal marks the commands as terminal programs e.g. w3m
+// that need to take over the terminal until they exit.
+func WithTerminal(terminal bool) func(*Opener) {
+	return func(o *Opener) {
+		o.terminal = terminal
+	}
+}
+
+func (o *Opener) Terminal() bool {
+	return o.terminal
+}
+
+// Command builds the command for rawURL without running it.
+func (o *Opener) Command(rawURL string, title string) (*exec.Cmd, error) {
+	domain := hostname(rawURL)
+	template := o.lookup(domain)
+	if template == "" {
+		return system(rawURL), nil
+	}
+
+	args, err := split(template)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 || args[0] == "" {
+		return nil, ErrEmptyCommand
+	}
+
+	replacer := strings.NewReplacer("{url}", rawURL, "{title}", title, "{domain}", domain)
+	hasURL := strings.Contains(template, "{url}")
+
+	for i, arg := range args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	if !hasURL {
+		args = append(args, rawURL)
+	}
+
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// Open builds the command for rawURL and starts it without waiting,
+// so e.g. a browser keeps running after anchor exits. Terminal commands
+// are attached to the standard streams and waited for instead.
+func (o *Opener) Open(rawURL string, title string) error {
+	cmd, err := o.Command(rawURL, title)
+	if err != nil {
+		return err
+	}
+
+	if o.terminal {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return cmd.Run()
+	}
+
+	return Start(cmd)
+}
+
+// Start starts cmd without waiting for it and releases it, so the
+// process is not tied to anchor and keeps running after it exits.
+func Start(cmd *exec.Cmd) error {
+	err := cmd.Start()
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd.Path, err)
+	}
+
+	return cmd.Process.Release()
+}
+
+// lookup returns the command of the longest configured
+// domain that is equal to or a parent of domain.
+func (o *Opener) lookup(domain string) string {
+	for d := domain; d != ""; {
+		if cmd, ok := o.domains[d]; ok {
+			return cmd
+		}
+
+		_, parent, ok := strings.Cut(d, ".")
+		if !ok {
+			break
+		}
+
+		d = parent
+	}
+
+	return o.command
+}
+
+func system(rawURL string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("cmd", "/c", "start", rawURL)
+	case "darwin":
+		return exec.Command("open", rawURL)
+	default:
+		return exec.Command("xdg-open", rawURL)
+	}
+}
+
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// split breaks s into arguments on unquoted whitespace. Single quotes
+// preserve everything literally while double quotes allow \" escapes.
+func split(s string) ([]string, error) {
+	var args []string
+	var curr strings.Builder
+	var quote rune
+	var escaped, inArg bool
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			curr.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+
+			curr.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, curr.String())
+				curr.Reset()
+				inArg = false
+			}
+		default:
+			curr.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, ErrUnclosedQuote
+	}
+
+	if inArg {
+		args = append(args, curr.String())
+	}
+
+	return args, nil
+}
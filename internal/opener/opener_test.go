@@ -0,0 +1,82 @@
+package opener
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	o := New(
+		WithCommand("firefox -P 'work profile'"),
+		WithDomain("github.com", "chromium --new-window {url}"),
+		WithDomain("www.docs.rs", `w3m -title "{title}" {url}`),
+	)
+
+	tsc := map[string]struct {
+		url   string
+		title string
+		want  []string
+	}{
+		"default-command": {
+			url:  "https://go.dev/ref/spec",
+			want: []string{"firefox", "-P", "work profile", "https://go.dev/ref/spec"},
+		},
+		"domain-override": {
+			url:  "https://github.com/golang/go",
+			want: []string{"chromium", "--new-window", "https://github.com/golang/go"},
+		},
+		"sub-domain-override": {
+			url:  "https://gist.github.com/abc",
+			want: []string{"chromium", "--new-window", "https://gist.github.com/abc"},
+		},
+		"title-placeholder": {
+			url:   "https://docs.rs/serde",
+			title: "serde docs",
+			want:  []string{"w3m", "-title", "serde docs", "https://docs.rs/serde"},
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			cmd, err := o.Command(c.url, c.title)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			if !cmp.Equal(c.want, cmd.Args) {
+				t.Error(cmp.Diff(c.want, cmd.Args))
+			}
+		})
+	}
+}
+
+func TestCommandInvalid(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]struct {
+		command string
+		err     error
+	}{
+		"empty": {
+			command: `""`,
+			err:     ErrEmptyCommand,
+		},
+		"unclosed-quote": {
+			command: `firefox "-P`,
+			err:     ErrUnclosedQuote,
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			_, err := New(WithCommand(c.command)).Command("https://go.dev", "")
+			if !errors.Is(err, c.err) {
+				t.Errorf("unexpected error; want %q, got %q", c.err, err)
+			}
+		})
+	}
+}
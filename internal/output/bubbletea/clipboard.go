@@ -0,0 +1,52 @@
+package bubbletea
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// copyToClipboard writes text to the local clipboard if there is one.
+// Over SSH or when no clipboard utility is available it falls back to an
+// OSC 52 escape sequence that is handled by the terminal emulator on the
+// client side, wrapped accordingly when running inside tmux or screen.
+func copyToClipboard(text string) error {
+	if !remoteSession() && !clipboard.Unsupported {
+		if err := clipboard.WriteAll(text); err == nil {
+			return nil
+		}
+	}
+
+	return writeOSC52(os.Stderr, text)
+}
+
+func writeOSC52(w io.Writer, text string) error {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+
+	_, err := seq.WriteTo(w)
+	return err
+}
+
+func remoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// markdownLink formats bk as a Markdown link, using the URL
+// as the link text if the bookmark has no title.
+func markdownLink(bk *model.Bookmark) string {
+	return fmt.Sprintf("[%s](%s)", markdownEscaper.Replace(cmp.Or(bk.Title(), bk.URL())), bk.URL())
+}
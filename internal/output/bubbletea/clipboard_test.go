@@ -0,0 +1,99 @@
+package bubbletea
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+func TestMarkdownLink(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]struct {
+		title string
+		want  string
+	}{
+		"plain-title": {
+			title: "Go",
+			want:  "[Go](https://go.dev)",
+		},
+		"closing-bracket": {
+			title: "Go] docs",
+			want:  `[Go\] docs](https://go.dev)`,
+		},
+		"opening-bracket": {
+			title: "[draft Go docs",
+			want:  `[\[draft Go docs](https://go.dev)`,
+		},
+		"both-brackets": {
+			title: "Go [beta] docs",
+			want:  `[Go \[beta\] docs](https://go.dev)`,
+		},
+		"backslash": {
+			title: `C:\ paths`,
+			want:  `[C:\\ paths](https://go.dev)`,
+		},
+		"empty-title": {
+			title: "",
+			want:  "[https://go.dev](https://go.dev)",
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			// A title is passed so no request is made to fetch one.
+			bk, err := model.NewBookmark("https://go.dev", model.WithTitle("placeholder"))
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			bk.Update(c.title)
+			got := markdownLink(bk)
+			if got != c.want {
+				t.Errorf("unexpected markdown link; want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteOSC52(t *testing.T) {
+	tsc := map[string]struct {
+		tmux string
+		term string
+		want string
+	}{
+		"plain": {
+			term: "xterm-256color",
+			want: "\x1b]52;c;aGk=\a",
+		},
+		"tmux": {
+			tmux: "/tmp/tmux-1000/default,1,0",
+			term: "screen-256color",
+			want: "\x1bPtmux;\x1b\x1b]52;c;aGk=\a\x1b\\",
+		},
+		"screen": {
+			term: "screen",
+			want: "\x1bP\x1b]52;c;aGk=\a\x1b\\",
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			t.Setenv("TMUX", c.tmux)
+			t.Setenv("TERM", c.term)
+
+			var buf bytes.Buffer
+			err := writeOSC52(&buf, "hi")
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			if buf.String() != c.want {
+				t.Errorf("unexpected escape sequence; want %q, got %q", c.want, buf.String())
+			}
+		})
+	}
+}
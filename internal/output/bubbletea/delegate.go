@@ -0,0 +1,109 @@
+package bubbletea
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/muesli/reflow/truncate"
+)
+
+const (
+	ellipsis = "…"
+)
+
+// itemDelegate renders bookmarks the same way as list.DefaultDelegate
+// but highlights filter matches on the field they were found in.
+// Matches on the URL, domain or comment replace the description line.
+type itemDelegate struct {
+	list.DefaultDelegate
+}
+
+func newItemDelegate() itemDelegate {
+	del := list.NewDefaultDelegate()
+	style.ApplyToDelegate(&del)
+
+	return itemDelegate{del}
+}
+
+func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	bk, ok := item.(*model.Bookmark)
+	if !ok || m.Width() <= 0 {
+		return
+	}
+
+	var (
+		s          = &d.Styles
+		title      = bk.Title()
+		desc       = bk.Description()
+		isSelected = index == m.Index()
+		isFiltered = m.FilterState() == list.Filtering || m.FilterState() == list.FilterApplied
+		isEmpty    = m.FilterState() == list.Filtering && m.FilterValue() == ""
+	)
+
+	var titleMatches, descMatches []int
+	if isFiltered {
+		f, matches := splitMatches(bk, m.MatchesForItem(index))
+		switch f {
+		case fieldTitle:
+			titleMatches = matches
+		case fieldURL:
+			desc, descMatches = bk.URL(), matches
+		case fieldComment:
+			desc, descMatches = bk.Comment(), matches
+		case fieldDomain:
+			desc, descMatches = bk.URL(), shift(matches, runeIndex(bk.URL(), bk.Domain()))
+		}
+	}
+
+	width := uint(m.Width() - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight())
+	title = truncate.StringWithTail(title, width, ellipsis)
+	desc = truncate.StringWithTail(desc, width, ellipsis)
+
+	switch {
+	case isEmpty:
+		title = s.DimmedTitle.Render(title)
+		desc = s.DimmedDesc.Render(desc)
+	case isSelected && m.FilterState() != list.Filtering:
+		title = s.SelectedTitle.Render(highlight(title, titleMatches, s.SelectedTitle, s.FilterMatch))
+		desc = s.SelectedDesc.Render(highlight(desc, descMatches, s.SelectedDesc, s.FilterMatch))
+	default:
+		title = s.NormalTitle.Render(highlight(title, titleMatches, s.NormalTitle, s.FilterMatch))
+		desc = s.NormalDesc.Render(highlight(desc, descMatches, s.NormalDesc, s.FilterMatch))
+	}
+
+	_, _ = fmt.Fprintf(w, "%s\n%s", title, desc)
+}
+
+func highlight(in string, matches []int, base lipgloss.Style, match lipgloss.Style) string {
+	if len(matches) == 0 {
+		return in
+	}
+
+	unmatched := base.Copy().Inline(true)
+	matched := unmatched.Copy().Inherit(match)
+	return lipgloss.StyleRunes(in, matches, matched, unmatched)
+}
+
+func runeIndex(s, substr string) int {
+	idx := strings.Index(s, substr)
+	if idx == -1 {
+		return 0
+	}
+
+	return utf8.RuneCountInString(s[:idx])
+}
+
+func shift(indexes []int, offset int) []int {
+	res := make([]int, len(indexes))
+	for i, idx := range indexes {
+		res[i] = idx + offset
+	}
+
+	return res
+}
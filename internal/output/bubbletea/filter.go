@@ -0,0 +1,155 @@
+package bubbletea
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/sahilm/fuzzy"
+)
+
+// field is the position of a bookmark field
+// inside the model.Bookmark FilterValue.
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldURL
+	fieldComment
+	fieldDomain
+)
+
+var allFields = []field{fieldTitle, fieldURL, fieldComment, fieldDomain}
+
+var fieldPrefixes = map[string]field{
+	"t":       fieldTitle,
+	"title":   fieldTitle,
+	"u":       fieldURL,
+	"url":     fieldURL,
+	"c":       fieldComment,
+	"comment": fieldComment,
+	"d":       fieldDomain,
+	"domain":  fieldDomain,
+}
+
+type scoredRank struct {
+	list.Rank
+	score int
+}
+
+// filterBookmarks is a list.FilterFunc that fuzzy matches the term on every
+// bookmark field and keeps the best scoring one. A term prefixed with a known
+// field name e.g. "url:github" or "c:todo" narrows the match to that field.
+//
+// Matched indexes are rune positions in the full filter value.
+func filterBookmarks(term string, targets []string) []list.Rank {
+	fields, pattern := parseTerm(term)
+
+	var scored []scoredRank
+	for i, target := range targets {
+		parts := strings.Split(target, model.FilterSeparator)
+
+		var best *fuzzy.Match
+		var bestField field
+		for _, f := range fields {
+			if int(f) >= len(parts) {
+				continue
+			}
+
+			if pattern == "" {
+				best = &fuzzy.Match{}
+				break
+			}
+
+			matches := fuzzy.Find(pattern, parts[f:f+1])
+			if len(matches) == 0 || (best != nil && matches[0].Score <= best.Score) {
+				continue
+			}
+
+			best, bestField = &matches[0], f
+		}
+
+		if best == nil {
+			continue
+		}
+
+		scored = append(scored, scoredRank{
+			Rank: list.Rank{
+				Index:          i,
+				MatchedIndexes: toRunes(parts, bestField, best.MatchedIndexes),
+			},
+			score: best.Score,
+		})
+	}
+
+	slices.SortStableFunc(scored, func(a, b scoredRank) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	res := make([]list.Rank, len(scored))
+	for i, s := range scored {
+		res[i] = s.Rank
+	}
+
+	return res
+}
+
+func parseTerm(term string) ([]field, string) {
+	prefix, pattern, ok := strings.Cut(term, ":")
+	if !ok {
+		return allFields, term
+	}
+
+	f, ok := fieldPrefixes[strings.ToLower(prefix)]
+	if !ok {
+		return allFields, term
+	}
+
+	return []field{f}, strings.TrimSpace(pattern)
+}
+
+// toRunes converts byte indexes inside parts[f] into
+// rune indexes inside the joined filter value.
+func toRunes(parts []string, f field, indexes []int) []int {
+	offset := int(f)
+	for _, p := range parts[:f] {
+		offset += utf8.RuneCountInString(p)
+	}
+
+	res := make([]int, len(indexes))
+	for i, idx := range indexes {
+		res[i] = offset + utf8.RuneCountInString(parts[f][:idx])
+	}
+
+	return res
+}
+
+// splitMatches maps rune indexes from the joined filter value back to the
+// field they belong to. Only the field of the first index is considered.
+func splitMatches(bk *model.Bookmark, matches []int) (field, []int) {
+	if len(matches) == 0 {
+		return fieldTitle, nil
+	}
+
+	var start int
+	for i, p := range strings.Split(bk.FilterValue(), model.FilterSeparator) {
+		end := start + utf8.RuneCountInString(p)
+		if matches[0] >= start && matches[0] <= end {
+			var res []int
+			for _, m := range matches {
+				if m >= start && m < end {
+					res = append(res, m-start)
+				}
+			}
+
+			return field(i), res
+		}
+
+		start = end + 1
+	}
+
+	return fieldTitle, nil
+}
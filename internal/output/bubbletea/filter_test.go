@@ -0,0 +1,103 @@
+package bubbletea
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+func TestFilterBookmarks(t *testing.T) {
+	t.Parallel()
+
+	bookmarks := []*model.Bookmark{
+		newTestBookmark(t, "Go by Example", "https://gobyexample.com", "01950975-fa76-7afc-b1e2-16255225c5d0"),
+		newTestBookmark(t, "Spec", "https://github.com/golang/go", "01950976-fa76-7afc-b1e2-16255225c5d0"),
+	}
+
+	commented, err := model.NewBookmark("https://zig.news", model.WithTitle("News"), model.WithComment("todo read later"))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	bookmarks = append(bookmarks, commented)
+	targets := make([]string, len(bookmarks))
+	for i, bk := range bookmarks {
+		targets[i] = bk.FilterValue()
+	}
+
+	tsc := map[string]struct {
+		term  string
+		want  []int
+		field field
+	}{
+		"title": {
+			term:  "example",
+			want:  []int{0},
+			field: fieldTitle,
+		},
+		"any-field-domain": {
+			term:  "github",
+			want:  []int{1},
+			field: fieldDomain,
+		},
+		"any-field-url": {
+			term:  "golang/go",
+			want:  []int{1},
+			field: fieldURL,
+		},
+		"comment-prefix": {
+			term:  "c:todo",
+			want:  []int{2},
+			field: fieldComment,
+		},
+		"domain-prefix": {
+			term:  "domain:zig",
+			want:  []int{2},
+			field: fieldDomain,
+		},
+		"prefix-narrows": {
+			term: "t:github",
+		},
+		"unknown-prefix": {
+			term:  "https:",
+			want:  []int{0, 1, 2},
+			field: fieldURL,
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			ranks := filterBookmarks(c.term, targets)
+
+			var got []int
+			for _, r := range ranks {
+				got = append(got, r.Index)
+			}
+
+			if !cmp.Equal(c.want, got, cmp.Transformer("sort", sortedInts)) {
+				t.Fatal(cmp.Diff(c.want, got))
+			}
+
+			for _, r := range ranks {
+				f, matches := splitMatches(bookmarks[r.Index], r.MatchedIndexes)
+				if f != c.field {
+					t.Errorf("unexpected matched field; want %d, got %d", c.field, f)
+				}
+
+				if len(matches) != len(r.MatchedIndexes) {
+					t.Errorf("matches span multiple fields; got %v", r.MatchedIndexes)
+				}
+			}
+		})
+	}
+}
+
+func sortedInts(in []int) map[int]bool {
+	res := map[int]bool{}
+	for _, i := range in {
+		res[i] = true
+	}
+
+	return res
+}
@@ -0,0 +1,248 @@
+package bubbletea
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+var (
+	ErrUnknownAction = errors.New("unknown key action")
+	ErrKeyConflict   = errors.New("conflicting key binding")
+)
+
+// KeyMap holds the bindings for every action of the View and is
+// the single source for both the behaviour and the help text.
+type KeyMap struct {
+	// Active while browsing the list.
+	Open        key.Binding
+	Archive     key.Binding
+	OpenArchive key.Binding
+	Preview     key.Binding
+	Snapshots   key.Binding
+	Delete      key.Binding
+	Rename      key.Binding
+	MoveUp      key.Binding
+	MoveDown    key.Binding
+	Sort        key.Binding
+	Yank        key.Binding
+	YankLink    key.Binding
+	PrevPage    key.Binding
+	NextPage    key.Binding
+
+	// Active while editing a bookmark.
+	Confirm   key.Binding
+	Cancel    key.Binding
+	LineStart key.Binding
+	LineEnd   key.Binding
+
+	// Active while browsing snapshots, along with Open to read one.
+	Mark key.Binding
+	Diff key.Binding
+
+	// Active while reading an archive or browsing snapshots.
+	Back key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Open:        newBinding("open", "enter"),
+		Archive:     newBinding("read archive", "a"),
+		OpenArchive: newBinding("open archive", "A"),
+		Preview:     newBinding("preview", "p"),
+		Snapshots:   newBinding("snapshots", "v"),
+		Delete:      newBinding("delete", "d", "delete"),
+		Rename:      newBinding("rename", "r"),
+		MoveUp:      newBinding("move up", "K", "shift+up"),
+		MoveDown:    newBinding("move down", "J", "shift+down"),
+		Sort:        newBinding("sort", "s"),
+		Yank:        newBinding("copy url", "y"),
+		YankLink:    newBinding("copy markdown link", "Y"),
+		PrevPage:    newBinding("prev page", "left", "h", "pgup"),
+		NextPage:    newBinding("next page", "right", "l", "pgdown"),
+		Confirm:     newBinding("confirm", "enter"),
+		Cancel:      newBinding("cancel", "esc"),
+		LineStart:   newBinding("line start", "home"),
+		LineEnd:     newBinding("line end", "end"),
+		Mark:        newBinding("mark base", "m"),
+		Diff:        newBinding("diff", "c"),
+		Back:        newBinding("back", "esc", "q"),
+	}
+}
+
+// NewKeyMap returns DefaultKeyMap with the keys of the given actions replaced.
+// Action names are the lower case field names separated by hyphen e.g. "move-up".
+//
+// Returns ErrUnknownAction for names that do not exist and ErrKeyConflict if
+// the same key ends up bound to more than one action in the same context.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	var err error
+	km := DefaultKeyMap()
+	actions := km.actions()
+
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			err = errors.Join(err, fmt.Errorf("%q: %w", name, ErrUnknownAction))
+			continue
+		}
+
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+
+	return km, errors.Join(err, km.validate())
+}
+
+func (km *KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Open, km.Delete, km.Rename, km.Archive, km.Preview, km.Sort, km.Yank}
+}
+
+func (km *KeyMap) FullHelp() []key.Binding {
+	return []key.Binding{km.Open, km.Delete, km.Rename, km.Archive, km.OpenArchive, km.Preview, km.Snapshots, km.Sort, km.MoveUp, km.MoveDown, km.Yank, km.YankLink}
+}
+
+func (km *KeyMap) SnapshotHelp() []key.Binding {
+	return []key.Binding{km.Open, km.Mark, km.Diff, km.Back}
+}
+
+func (km *KeyMap) actions() map[string]*key.Binding {
+	res := km.listActions()
+	for name, b := range km.inputActions() {
+		res[name] = b
+	}
+
+	for name, b := range km.readerActions() {
+		res[name] = b
+	}
+
+	for name, b := range km.snapshotActions() {
+		res[name] = b
+	}
+
+	return res
+}
+
+func (km *KeyMap) listActions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"open":         &km.Open,
+		"archive":      &km.Archive,
+		"open-archive": &km.OpenArchive,
+		"preview":      &km.Preview,
+		"snapshots":    &km.Snapshots,
+		"delete":       &km.Delete,
+		"rename":       &km.Rename,
+		"move-up":      &km.MoveUp,
+		"move-down":    &km.MoveDown,
+		"sort":         &km.Sort,
+		"yank":         &km.Yank,
+		"yank-link":    &km.YankLink,
+		"prev-page":    &km.PrevPage,
+		"next-page":    &km.NextPage,
+	}
+}
+
+func (km *KeyMap) inputActions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"confirm":    &km.Confirm,
+		"cancel":     &km.Cancel,
+		"line-start": &km.LineStart,
+		"line-end":   &km.LineEnd,
+	}
+}
+
+func (km *KeyMap) snapshotActions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"open": &km.Open,
+		"mark": &km.Mark,
+		"diff": &km.Diff,
+		"back": &km.Back,
+	}
+}
+
+func (km *KeyMap) readerActions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"back": &km.Back,
+	}
+}
+
+// validate checks for keys shared by actions of the same context.
+// Actions are also checked against the built-in keys of list.Model and
+// viewport.Model since the View handles them first and would shadow the
+// built-in ones. The snapshot list has no filter and no quit keys.
+func (km *KeyMap) validate() error {
+	builtin := list.DefaultKeyMap()
+	scroll := viewport.DefaultKeyMap()
+
+	return errors.Join(
+		conflicts(km.listActions(), map[string]key.Binding{
+			"cursor-up":      builtin.CursorUp,
+			"cursor-down":    builtin.CursorDown,
+			"go-to-start":    builtin.GoToStart,
+			"go-to-end":      builtin.GoToEnd,
+			"filter":         builtin.Filter,
+			"clear-filter":   builtin.ClearFilter,
+			"show-full-help": builtin.ShowFullHelp,
+			"quit":           builtin.Quit,
+			"force-quit":     builtin.ForceQuit,
+		}),
+		conflicts(km.snapshotActions(), map[string]key.Binding{
+			"cursor-up":      builtin.CursorUp,
+			"cursor-down":    builtin.CursorDown,
+			"go-to-start":    builtin.GoToStart,
+			"go-to-end":      builtin.GoToEnd,
+			"prev-page":      km.PrevPage,
+			"next-page":      km.NextPage,
+			"show-full-help": builtin.ShowFullHelp,
+			"force-quit":     builtin.ForceQuit,
+		}),
+		conflicts(km.inputActions(), nil),
+		conflicts(km.readerActions(), map[string]key.Binding{
+			"page-down":      scroll.PageDown,
+			"page-up":        scroll.PageUp,
+			"half-page-up":   scroll.HalfPageUp,
+			"half-page-down": scroll.HalfPageDown,
+			"up":             scroll.Up,
+			"down":           scroll.Down,
+		}))
+}
+
+func conflicts(actions map[string]*key.Binding, reserved map[string]key.Binding) error {
+	seen := map[string]string{}
+	for name, b := range reserved {
+		for _, k := range b.Keys() {
+			seen[k] = name
+		}
+	}
+
+	// Sort names to report conflicts in a stable order.
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var err error
+	for _, name := range names {
+		for _, k := range actions[name].Keys() {
+			if other, ok := seen[k]; ok {
+				err = errors.Join(err, fmt.Errorf("%q used by %q and %q: %w", k, other, name, ErrKeyConflict))
+				continue
+			}
+
+			seen[k] = name
+		}
+	}
+
+	return err
+}
+
+func newBinding(help string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), help))
+}
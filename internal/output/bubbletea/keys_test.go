@@ -0,0 +1,82 @@
+package bubbletea
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDefaultKeyMap(t *testing.T) {
+	t.Parallel()
+
+	km := DefaultKeyMap()
+	if err := km.validate(); err != nil {
+		t.Errorf("unexpected error; got %q", err)
+	}
+}
+
+func TestNewKeyMap(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]struct {
+		overrides map[string][]string
+		err       error
+	}{
+		"no-overrides": {
+			overrides: nil,
+		},
+		"valid-override": {
+			overrides: map[string][]string{"delete": {"x"}},
+		},
+		"unknown-action": {
+			overrides: map[string][]string{"explode": {"x"}},
+			err:       ErrUnknownAction,
+		},
+		"conflict-between-actions": {
+			overrides: map[string][]string{"rename": {"d"}},
+			err:       ErrKeyConflict,
+		},
+		"conflict-with-builtin": {
+			overrides: map[string][]string{"sort": {"j"}},
+			err:       ErrKeyConflict,
+		},
+		"conflict-with-reader-builtin": {
+			overrides: map[string][]string{"back": {"j"}},
+			err:       ErrKeyConflict,
+		},
+		"conflict-with-snapshot-builtin": {
+			overrides: map[string][]string{"diff": {"j"}},
+			err:       ErrKeyConflict,
+		},
+		"different-contexts": {
+			overrides: map[string][]string{"confirm": {"a"}},
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			_, err := NewKeyMap(c.overrides)
+			if !errors.Is(err, c.err) {
+				t.Errorf("unexpected error; want %q, got %q", c.err, err)
+			}
+		})
+	}
+}
+
+func TestNewKeyMapHelp(t *testing.T) {
+	t.Parallel()
+
+	km, err := NewKeyMap(map[string][]string{"delete": {"x", "backspace"}})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if !cmp.Equal(km.Delete.Keys(), []string{"x", "backspace"}) {
+		t.Error(cmp.Diff([]string{"x", "backspace"}, km.Delete.Keys()))
+	}
+
+	if km.Delete.Help().Key != "x/backspace" {
+		t.Errorf("help not updated; got %q", km.Delete.Help().Key)
+	}
+}
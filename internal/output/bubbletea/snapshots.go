@@ -0,0 +1,110 @@
+package bubbletea
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/loghinalexandru/anchor/internal/archive"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/loghinalexandru/anchor/internal/output/reader"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// snapshotLayout is how snapshot times are shown in the TUI.
+const snapshotLayout = "2006-01-02 15:04:05"
+
+// snapshotItem adapts archive.Snapshot to list.DefaultItem.
+type snapshotItem struct {
+	archive.Snapshot
+	base bool
+}
+
+func (s snapshotItem) Title() string {
+	if s.base {
+		return s.Time.Local().Format(snapshotLayout) + " (base)"
+	}
+
+	return s.Time.Local().Format(snapshotLayout)
+}
+
+func (s snapshotItem) Description() string {
+	return s.Path
+}
+
+func (s snapshotItem) FilterValue() string {
+	return s.Title()
+}
+
+func newSnapshotList() list.Model {
+	delegate := list.NewDefaultDelegate()
+	delegate.ShowDescription = false
+	style.ApplyToDelegate(&delegate)
+
+	res := list.New(nil, delegate, 0, 0)
+	style.ApplyToList("", &res)
+	res.SetFilteringEnabled(false)
+	res.SetShowStatusBar(false)
+	res.KeyMap.Quit.SetEnabled(false)
+
+	return res
+}
+
+// preview renders the first paragraphs of an HTML or text snapshot.
+func preview(snapshot archive.Snapshot, width int) (string, error) {
+	raw, err := os.ReadFile(snapshot.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if !snapshot.Text() {
+		return reader.Preview(bytes.NewReader(archive.Content(raw)), width, previewBlocks)
+	}
+
+	lines := strings.SplitN(string(raw), "\n", previewLines+1)
+	return wordwrap.String(strings.Join(lines[:min(len(lines), previewLines)], "\n"), width), nil
+}
+
+// snapshotItems flags the snapshot stored at base, if any.
+func snapshotItems(snapshots []archive.Snapshot, base string) []list.Item {
+	res := make([]list.Item, len(snapshots))
+	for i, s := range snapshots {
+		res[i] = snapshotItem{Snapshot: s, base: base != "" && s.Path == base}
+	}
+
+	return res
+}
+
+// renderDiff marks inserted and deleted lines and wraps
+// every line so that the markers stay on the left side.
+func renderDiff(lines []archive.Line, width int) string {
+	var res []string
+	changed := false
+
+	for _, l := range lines {
+		marker, st := "  ", lipgloss.NewStyle()
+		switch l.Op {
+		case archive.Insert:
+			marker, st, changed = "+ ", style.Inserted(), true
+		case archive.Delete:
+			marker, st, changed = "- ", style.Deleted(), true
+		}
+
+		wrapped := strings.Split(wordwrap.String(l.Text, max(width-len(marker), 1)), "\n")
+		for i, w := range wrapped {
+			if i > 0 {
+				marker = strings.Repeat(" ", len(marker))
+			}
+
+			res = append(res, st.Render(marker+w))
+		}
+	}
+
+	if !changed {
+		return style.Muted().Render(msgNoChanges)
+	}
+
+	return strings.Join(res, "\n")
+}
@@ -0,0 +1,79 @@
+package bubbletea
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+var (
+	ErrInvalidSortMode = errors.New("invalid sort mode")
+)
+
+// SortMode controls the order in which bookmarks are displayed.
+// Only Manual reflects the order persisted on the storage.
+type SortMode int
+
+const (
+	Manual SortMode = iota
+	ByTitle
+	ByDomain
+	ByAdded
+	ByOpened
+)
+
+var sortModeNames = []string{"manual", "title", "domain", "added", "opened"}
+
+func ParseSortMode(s string) (SortMode, error) {
+	idx := slices.Index(sortModeNames, strings.ToLower(strings.TrimSpace(s)))
+	if idx == -1 {
+		return Manual, fmt.Errorf("%q: %w", s, ErrInvalidSortMode)
+	}
+
+	return SortMode(idx), nil
+}
+
+func (m SortMode) String() string {
+	return sortModeNames[m]
+}
+
+func (m SortMode) next() SortMode {
+	return (m + 1) % SortMode(len(sortModeNames))
+}
+
+// sortBookmarks returns a sorted copy of bookmarks leaving the input untouched.
+// Newest entries come first when sorting by time.
+func sortBookmarks(bookmarks []*model.Bookmark, mode SortMode, history model.History) []*model.Bookmark {
+	res := slices.Clone(bookmarks)
+
+	switch mode {
+	case ByTitle:
+		slices.SortStableFunc(res, compareTitle)
+	case ByDomain:
+		slices.SortStableFunc(res, func(a, b *model.Bookmark) int {
+			if c := strings.Compare(a.Domain(), b.Domain()); c != 0 {
+				return c
+			}
+
+			return compareTitle(a, b)
+		})
+	case ByAdded:
+		slices.SortStableFunc(res, func(a, b *model.Bookmark) int {
+			return b.Added().Compare(a.Added())
+		})
+	case ByOpened:
+		slices.SortStableFunc(res, func(a, b *model.Bookmark) int {
+			return history.LastOpened(b.Id()).Compare(history.LastOpened(a.Id()))
+		})
+	case Manual:
+	}
+
+	return res
+}
+
+func compareTitle(a, b *model.Bookmark) int {
+	return strings.Compare(strings.ToLower(a.Title()), strings.ToLower(b.Title()))
+}
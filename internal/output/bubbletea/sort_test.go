@@ -0,0 +1,121 @@
+package bubbletea
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+func TestParseSortMode(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]struct {
+		input string
+		want  SortMode
+		err   error
+	}{
+		"manual": {
+			input: "manual",
+			want:  Manual,
+		},
+		"mixed-case": {
+			input: " Domain ",
+			want:  ByDomain,
+		},
+		"invalid": {
+			input: "random",
+			want:  Manual,
+			err:   ErrInvalidSortMode,
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			got, err := ParseSortMode(c.input)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("unexpected error; want %q, got %q", c.err, err)
+			}
+
+			if got != c.want {
+				t.Errorf("unexpected sort mode; want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestSortBookmarks(t *testing.T) {
+	t.Parallel()
+
+	first := newTestBookmark(t, "Zig", "https://ziglang.org", "01950975-fa76-7afc-b1e2-16255225c5d0")
+	second := newTestBookmark(t, "go", "https://www.go.dev", "01950976-fa76-7afc-b1e2-16255225c5d0")
+	third := newTestBookmark(t, "Alpha", "https://alpha.com", "01950977-fa76-7afc-b1e2-16255225c5d0")
+	input := []*model.Bookmark{first, second, third}
+
+	history := model.History{
+		second.Id(): time.Now(),
+		first.Id():  time.Now().Add(-time.Hour),
+	}
+
+	tsc := map[string]struct {
+		mode SortMode
+		want []*model.Bookmark
+	}{
+		"manual": {
+			mode: Manual,
+			want: []*model.Bookmark{first, second, third},
+		},
+		"title": {
+			mode: ByTitle,
+			want: []*model.Bookmark{third, second, first},
+		},
+		"domain": {
+			mode: ByDomain,
+			want: []*model.Bookmark{third, second, first},
+		},
+		"added": {
+			mode: ByAdded,
+			want: []*model.Bookmark{third, second, first},
+		},
+		"opened": {
+			mode: ByOpened,
+			want: []*model.Bookmark{second, first, third},
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			got := sortBookmarks(input, c.mode, history)
+			if !cmp.Equal(ids(c.want), ids(got)) {
+				t.Error(cmp.Diff(ids(c.want), ids(got)))
+			}
+		})
+	}
+
+	if !cmp.Equal(ids(input), ids([]*model.Bookmark{first, second, third})) {
+		t.Error("input slice was modified")
+	}
+}
+
+func newTestBookmark(t *testing.T, title, url, id string) *model.Bookmark {
+	t.Helper()
+
+	bk, err := model.NewBookmark(url, model.WithTitle(title), model.WithId(id))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	return bk
+}
+
+func ids(bookmarks []*model.Bookmark) []uuid.UUID {
+	res := make([]uuid.UUID, len(bookmarks))
+	for i, bk := range bookmarks {
+		res[i] = bk.Id()
+	}
+
+	return res
+}
@@ -1,7 +1,11 @@
 package style
 
 import (
-	"github.com/charmbracelet/bubbles/key"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/paginator"
 	"github.com/charmbracelet/lipgloss"
@@ -10,25 +14,193 @@ import (
 type RenderFunc func(in string) string
 
 var (
-	stdStyle       = lipgloss.NewStyle().Margin(2, 2, 2, 2)
-	stdPromptStyle = lipgloss.NewStyle().Margin(0, 0, 0, 2)
+	ErrUnknownTheme      = errors.New("unknown theme")
+	ErrUnknownThemeField = errors.New("unknown theme field")
 )
 
+// Theme holds the colors and spacing used across the TUI. Colors accept
+// anything lipgloss.Color does e.g. "#7D56F4" or ANSI "12" and empty
+// values fall back to the terminal defaults.
+type Theme struct {
+	Text            string
+	Muted           string
+	Accent          string
+	Highlight       string
+	TitleText       string
+	TitleBackground string
+	Prompt          string
+	Margin          int
+}
+
+const (
+	StdTheme = "default"
+)
+
+var themes = map[string]Theme{
+	StdTheme: {
+		Margin: 2,
+	},
+	"dark": {
+		Text:            "#DDDDDD",
+		Muted:           "#777777",
+		Accent:          "#AD8CFF",
+		Highlight:       "#FFD866",
+		TitleText:       "#1A1A1A",
+		TitleBackground: "#AD8CFF",
+		Prompt:          "#AD8CFF",
+		Margin:          2,
+	},
+	"light": {
+		Text:            "#1A1A1A",
+		Muted:           "#7A7A7A",
+		Accent:          "#5A3FC0",
+		Highlight:       "#C2410C",
+		TitleText:       "#FFFFFF",
+		TitleBackground: "#5A3FC0",
+		Prompt:          "#5A3FC0",
+		Margin:          2,
+	},
+	"high-contrast": {
+		Text:            "15",
+		Muted:           "7",
+		Accent:          "11",
+		Highlight:       "14",
+		TitleText:       "0",
+		TitleBackground: "11",
+		Prompt:          "15",
+		Margin:          2,
+	},
+}
+
+var current = themes[StdTheme]
+
+// Use sets the theme for every style in the package.
+// If NO_COLOR is set, only the spacing of the theme is kept.
+func Use(t Theme) {
+	if os.Getenv("NO_COLOR") != "" {
+		t = Theme{Margin: t.Margin}
+	}
+
+	current = t
+}
+
+// Resolve returns the theme called name looking first into the user defined ones.
+// A user defined theme starts from the theme set under its "base" field or the
+// default theme and overrides only the fields present.
+func Resolve(name string, custom map[string]map[string]string) (Theme, error) {
+	fields, ok := custom[name]
+	if !ok {
+		t, ok := themes[name]
+		if !ok {
+			return themes[StdTheme], fmt.Errorf("%q: %w", name, ErrUnknownTheme)
+		}
+
+		return t, nil
+	}
+
+	base := themes[StdTheme]
+	if b, ok := fields["base"]; ok {
+		base, ok = themes[b]
+		if !ok {
+			return themes[StdTheme], fmt.Errorf("%q: %w", b, ErrUnknownTheme)
+		}
+	}
+
+	return base.with(fields)
+}
+
+func (t Theme) with(fields map[string]string) (Theme, error) {
+	var err error
+	for k, v := range fields {
+		switch k {
+		case "base":
+		case "text":
+			t.Text = v
+		case "muted":
+			t.Muted = v
+		case "accent":
+			t.Accent = v
+		case "highlight":
+			t.Highlight = v
+		case "title-text":
+			t.TitleText = v
+		case "title-background":
+			t.TitleBackground = v
+		case "prompt":
+			t.Prompt = v
+		case "margin":
+			margin, convErr := strconv.Atoi(v)
+			if convErr != nil {
+				err = errors.Join(err, fmt.Errorf("margin: %w", convErr))
+				continue
+			}
+
+			t.Margin = margin
+		default:
+			err = errors.Join(err, fmt.Errorf("%q: %w", k, ErrUnknownThemeField))
+		}
+	}
+
+	return t, err
+}
+
 func Nop(in string) string {
 	return in
 }
 
 func Prompt(in string) string {
-	return stdPromptStyle.Render(in)
+	return foreground(lipgloss.NewStyle().Margin(0, 0, 0, current.Margin), current.Prompt).Render(in)
+}
+
+// Confirm renders confirmation prompts outside the TUI
+// with the theme colors but without any margin.
+func Confirm(in string) string {
+	return foreground(lipgloss.NewStyle(), current.Prompt).Render(in)
 }
 
 func Default() lipgloss.Style {
-	return stdStyle
+	return lipgloss.NewStyle().Margin(current.Margin)
+}
+
+// Heading, Link and Code style archived pages rendered as terminal text.
+func Heading() lipgloss.Style {
+	return foreground(lipgloss.NewStyle().Bold(true), current.Accent)
+}
+
+func Link() lipgloss.Style {
+	return foreground(lipgloss.NewStyle().Underline(true), current.Highlight)
+}
+
+func Code() lipgloss.Style {
+	return foreground(lipgloss.NewStyle(), current.Muted)
+}
+
+func Muted() lipgloss.Style {
+	return foreground(lipgloss.NewStyle().Faint(current.Muted == ""), current.Muted)
+}
+
+// Inserted and Deleted style the lines of a diff between archive snapshots.
+func Inserted() lipgloss.Style {
+	return foreground(lipgloss.NewStyle().Bold(true), current.Accent)
+}
+
+func Deleted() lipgloss.Style {
+	return foreground(lipgloss.NewStyle().Strikethrough(true), current.Muted)
+}
+
+// Match highlights the words of a search result matching the query.
+func Match() lipgloss.Style {
+	return foreground(lipgloss.NewStyle().Bold(true), current.Highlight)
 }
 
 func ApplyToDelegate(del *list.DefaultDelegate) {
-	del.Styles.SelectedTitle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.NoColor{})
-	del.Styles.SelectedDesc = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.NoColor{})
+	del.Styles.SelectedTitle = foreground(lipgloss.NewStyle().Bold(true), current.Accent)
+	del.Styles.SelectedDesc = foreground(lipgloss.NewStyle().Bold(true), current.Accent)
+	del.Styles.NormalTitle = foreground(del.Styles.NormalTitle, current.Text)
+	del.Styles.NormalDesc = foreground(del.Styles.NormalDesc, current.Muted)
+	del.Styles.DimmedTitle = foreground(del.Styles.DimmedTitle, current.Muted)
+	del.Styles.DimmedDesc = foreground(del.Styles.DimmedDesc, current.Muted)
+	del.Styles.FilterMatch = foreground(del.Styles.FilterMatch, current.Highlight)
 }
 
 func ApplyToList(title string, list *list.Model) {
@@ -36,29 +208,27 @@ func ApplyToList(title string, list *list.Model) {
 	list.InfiniteScrolling = true
 	list.Paginator.Type = paginator.Arabic
 	list.Paginator.ArabicFormat = "%d/%d \u2693"
-	list.FilterInput.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.NoColor{})
-	list.FilterInput.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.NoColor{})
-
-	list.KeyMap.PrevPage.SetKeys("left", "h", "pgup")
-	list.KeyMap.NextPage.SetKeys("right", "l", "pgdown")
-
-	// This is duplicated in the commands as well. Need to move
-	// in a central config place maybe.
-	list.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			key.NewBinding(key.WithKeys("enter", "space"), key.WithHelp("enter", "open")),
-			key.NewBinding(key.WithKeys("delete", "d"), key.WithHelp("d/del", "delete")),
-			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename")),
-			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "archive")),
-		}
+	list.FilterInput.PromptStyle = foreground(lipgloss.NewStyle(), current.Prompt)
+	list.FilterInput.Cursor.Style = foreground(lipgloss.NewStyle(), current.Prompt)
+	list.Styles.Title = background(foreground(list.Styles.Title, current.TitleText), current.TitleBackground)
+	list.Styles.StatusBar = foreground(list.Styles.StatusBar, current.Muted)
+	list.Styles.HelpStyle = foreground(list.Styles.HelpStyle, current.Muted)
+}
+
+// foreground sets the color if present, otherwise the style
+// keeps the built-in colors of bubbles or the terminal default.
+func foreground(s lipgloss.Style, color string) lipgloss.Style {
+	if color == "" {
+		return s
 	}
 
-	list.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			key.NewBinding(key.WithKeys("enter", "space"), key.WithHelp("enter/space", "open in browser")),
-			key.NewBinding(key.WithKeys("delete", "d"), key.WithHelp("d/del", "remove bookmark")),
-			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename bookmark")),
-			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "view archived page")),
-		}
+	return s.Copy().Foreground(lipgloss.Color(color))
+}
+
+func background(s lipgloss.Style, color string) lipgloss.Style {
+	if color == "" {
+		return s
 	}
+
+	return s.Copy().Background(lipgloss.Color(color))
 }
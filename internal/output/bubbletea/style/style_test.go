@@ -0,0 +1,83 @@
+package style
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	custom := map[string]map[string]string{
+		"mine": {
+			"base":   "dark",
+			"accent": "#FF0000",
+			"margin": "1",
+		},
+		"plain": {
+			"prompt": "12",
+		},
+		"broken": {
+			"shadow": "#000000",
+		},
+		"orphan": {
+			"base": "missing",
+		},
+	}
+
+	mine := themes["dark"]
+	mine.Accent = "#FF0000"
+	mine.Margin = 1
+
+	plain := themes[StdTheme]
+	plain.Prompt = "12"
+
+	tsc := map[string]struct {
+		name string
+		want Theme
+		err  error
+	}{
+		"built-in": {
+			name: "high-contrast",
+			want: themes["high-contrast"],
+		},
+		"custom-with-base": {
+			name: "mine",
+			want: mine,
+		},
+		"custom-without-base": {
+			name: "plain",
+			want: plain,
+		},
+		"unknown-theme": {
+			name: "neon",
+			want: themes[StdTheme],
+			err:  ErrUnknownTheme,
+		},
+		"unknown-field": {
+			name: "broken",
+			want: themes[StdTheme],
+			err:  ErrUnknownThemeField,
+		},
+		"unknown-base": {
+			name: "orphan",
+			want: themes[StdTheme],
+			err:  ErrUnknownTheme,
+		},
+	}
+
+	for k, c := range tsc {
+		t.Run(k, func(t *testing.T) {
+			got, err := Resolve(c.name, custom)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("unexpected error; want %q, got %q", c.err, err)
+			}
+
+			if !cmp.Equal(c.want, got) {
+				t.Error(cmp.Diff(c.want, got))
+			}
+		})
+	}
+}
@@ -1,33 +1,56 @@
 package bubbletea
 
 import (
+	"bytes"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
-	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/archive"
 	"github.com/loghinalexandru/anchor/internal/model"
+	"github.com/loghinalexandru/anchor/internal/opener"
 	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/loghinalexandru/anchor/internal/output/reader"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
 )
 
 const (
-	msgStatus = "Deleted %q"
+	msgStatus       = "Deleted %q"
+	msgSorted       = "Sorted by %s"
+	msgManualOnly   = "Switch to manual sort to move items"
+	msgFiltered     = "Clear the filter to move items"
+	msgCopied       = "Copied %q"
+	msgCopyFailed   = "Copy failed: %s"
+	msgOpenFailed   = "Open failed: %s"
+	msgNoArchive    = "No archive for %q"
+	msgNoPreview    = "No archive available"
+	msgNoPreviewFor = "No preview for %s archives"
+	msgNoOlder      = "No older snapshot to compare with"
+	msgMarked       = "Marked %s as base"
+	msgUnmarked     = "Cleared base, comparing with the previous snapshot"
+	msgNoChanges    = "No changes between snapshots"
+	msgSnapshots    = "Snapshots of %q"
 )
 
-var (
-	quitKey    = key.NewBinding(key.WithKeys("esc"))
-	confirmKey = key.NewBinding(key.WithKeys("enter"))
-	archiveKey = key.NewBinding(key.WithKeys("a"))
-	delKey     = key.NewBinding(key.WithKeys("d", "delete"))
-	renameKey  = key.NewBinding(key.WithKeys("r"))
-	startKey   = key.NewBinding(key.WithKeys("home"))
-	endKey     = key.NewBinding(key.WithKeys("end"))
+const (
+	// previewBlocks is the number of paragraphs shown in the preview pane.
+	previewBlocks = 5
+	// previewLines is the number of lines shown for plain text archives.
+	previewLines = 20
+	// readerWidth is the maximum line width of the reader for readability.
+	readerWidth = 100
+	// readerChrome is the number of lines taken by the reader header and footer.
+	readerChrome = 4
 )
 
 type operation int
@@ -42,38 +65,113 @@ type action struct {
 	Operation operation
 }
 
+// openedMsg is sent after the opener command started, or finished
+// for terminal openers.
+type openedMsg struct {
+	err error
+}
+
 type View struct {
 	input     textinput.Model
 	bookmarks list.Model
+	order     []*model.Bookmark
+	history   model.History
+	keys      KeyMap
+	opener    *opener.Opener
+	sort      SortMode
 	actions   []action
 	dirty     bool
-}
 
-func NewView(bookmarks []list.Item, title string) *View {
-	del := list.NewDefaultDelegate()
-	style.ApplyToDelegate(&del)
+	// Archive reader, snapshot list and preview pane state.
+	reader    viewport.Model
+	reading   *model.Bookmark
+	caption   string
+	content   func(width int) (string, error)
+	snapshots list.Model
+	browsing  *model.Bookmark
+	base      string
+	preview   bool
+	previews  map[uuid.UUID]string
+	width     int
+	height    int
+}
 
-	viewList := list.New(bookmarks, del, 0, 0)
+func NewView(bookmarks []*model.Bookmark, title string, opts ...func(*View)) *View {
+	viewList := list.New(nil, newItemDelegate(), 0, 0)
+	viewList.Filter = filterBookmarks
 	style.ApplyToList(title, &viewList)
 
-	input := textinput.New()
-	input.KeyMap.LineStart = startKey
-	input.KeyMap.LineEnd = endKey
-
-	return &View{
-		input:     input,
+	res := &View{
+		input:     textinput.New(),
 		bookmarks: viewList,
+		order:     bookmarks,
+		history:   model.History{},
+		keys:      DefaultKeyMap(),
+		opener:    opener.New(),
+		reader:    viewport.New(0, 0),
+		snapshots: newSnapshotList(),
+		previews:  map[uuid.UUID]string{},
 	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	res.input.KeyMap.LineStart = res.keys.LineStart
+	res.input.KeyMap.LineEnd = res.keys.LineEnd
+	res.bookmarks.KeyMap.PrevPage = res.keys.PrevPage
+	res.bookmarks.KeyMap.NextPage = res.keys.NextPage
+	res.bookmarks.AdditionalShortHelpKeys = res.keys.ShortHelp
+	res.bookmarks.AdditionalFullHelpKeys = res.keys.FullHelp
+	res.snapshots.KeyMap.PrevPage = res.keys.PrevPage
+	res.snapshots.KeyMap.NextPage = res.keys.NextPage
+	res.snapshots.AdditionalShortHelpKeys = res.keys.SnapshotHelp
+	res.snapshots.AdditionalFullHelpKeys = res.keys.SnapshotHelp
+	res.bookmarks.SetItems(res.items())
+	return res
 }
 
-func (v *View) Bookmarks() []*model.Bookmark {
-	res := make([]*model.Bookmark, len(v.bookmarks.Items()))
+// WithOpener sets the opener used for bookmarks and archives.
+func WithOpener(o *opener.Opener) func(*View) {
+	return func(v *View) {
+		if o != nil {
+			v.opener = o
+		}
+	}
+}
 
-	for i, bk := range v.bookmarks.Items() {
-		res[i] = bk.(*model.Bookmark)
+// WithKeyMap replaces the DefaultKeyMap of the view.
+func WithKeyMap(keys KeyMap) func(*View) {
+	return func(v *View) {
+		v.keys = keys
 	}
+}
 
-	return res
+// WithSortMode sets the initial display order of the bookmarks.
+func WithSortMode(mode SortMode) func(*View) {
+	return func(v *View) {
+		v.sort = mode
+	}
+}
+
+// WithHistory sets the history used for sorting by last opened.
+// Opening a bookmark from the view updates it in place.
+func WithHistory(history model.History) func(*View) {
+	return func(v *View) {
+		if history != nil {
+			v.history = history
+		}
+	}
+}
+
+// Bookmarks returns the bookmarks in manual order regardless
+// of the sort mode currently displayed.
+func (v *View) Bookmarks() []*model.Bookmark {
+	return v.order
+}
+
+func (v *View) History() model.History {
+	return v.history
 }
 
 func (v *View) Actions() []action {
@@ -89,15 +187,69 @@ func (v *View) Init() tea.Cmd {
 }
 
 func (v *View) View() string {
+	if v.reading != nil {
+		return style.Default().Render(v.readerView())
+	}
+
+	if v.browsing != nil {
+		return style.Default().Render(v.snapshots.View())
+	}
+
 	if v.input.Focused() {
 		v.bookmarks.SetShowPagination(false)
 		v.bookmarks.SetShowHelp(false)
-		return style.Default().Render(v.bookmarks.View() + "\n" + v.input.View())
+		return style.Default().Render(v.listView() + "\n" + v.input.View())
 	}
 
 	v.bookmarks.SetShowPagination(true)
 	v.bookmarks.SetShowHelp(true)
-	return style.Default().Render(v.bookmarks.View())
+	return style.Default().Render(v.listView())
+}
+
+func (v *View) listView() string {
+	if !v.preview {
+		return v.bookmarks.View()
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, v.bookmarks.View(), v.previewView())
+}
+
+// previewView renders the first paragraphs of the archive of the selected
+// bookmark. Rendered previews are cached until the pane is resized.
+func (v *View) previewView() string {
+	width := v.width - v.listWidth() - 2
+	pane := lipgloss.NewStyle().
+		Width(width).
+		MaxHeight(v.height).
+		PaddingLeft(2)
+
+	item, ok := v.bookmarks.SelectedItem().(*model.Bookmark)
+	if !ok {
+		return pane.Render("")
+	}
+
+	content, ok := v.previews[item.Id()]
+	if !ok {
+		content = style.Muted().Render(msgNoPreview)
+		if latest, ok := archive.Latest(item.Id()); ok {
+			content = style.Muted().Render(fmt.Sprintf(msgNoPreviewFor, strings.TrimPrefix(filepath.Ext(latest.Path), ".")))
+			if text, err := preview(latest, width); err == nil && text != "" {
+				content = text
+			}
+		}
+
+		v.previews[item.Id()] = content
+	}
+
+	return pane.Render(content)
+}
+
+func (v *View) readerView() string {
+	header := style.Heading().Render(truncate.StringWithTail(v.reading.Title(), uint(v.width), "…")) + "\n" +
+		style.Muted().Render(truncate.StringWithTail(v.caption, uint(v.width), "…"))
+	footer := style.Muted().Render(fmt.Sprintf("%3.f%% • %s %s", v.reader.ScrollPercent()*100, v.keys.Back.Help().Key, v.keys.Back.Help().Desc))
+
+	return header + "\n\n" + v.reader.View() + "\n" + footer
 }
 
 func (v *View) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -109,11 +261,28 @@ func (v *View) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		x, y := style.Default().GetFrameSize()
 		width := msg.Width - x
 		height := msg.Height - y
+		v.width, v.height = width, height
 		v.input.Width = width - x
-		v.bookmarks.SetSize(width, height)
+		v.bookmarks.SetSize(v.listWidth(), height)
+		v.snapshots.SetSize(width, height)
 		v.bookmarks, inputCmd = v.bookmarks.Update(msg)
 		v.input, viewCmd = v.input.Update(msg)
+		v.resizeReader()
+	case openedMsg:
+		if msg.err != nil {
+			viewCmd = v.bookmarks.NewStatusMessage(fmt.Sprintf(msgOpenFailed, msg.err))
+		}
 	case tea.KeyMsg:
+		if v.reading != nil {
+			v.reader, viewCmd = v.handleReader(msg)
+			break
+		}
+
+		if v.browsing != nil {
+			v.snapshots, viewCmd = v.handleSnapshots(msg)
+			break
+		}
+
 		// Bypass "msg" input pipeline if setting filter value.
 		if v.bookmarks.SettingFilter() {
 			v.bookmarks, viewCmd = v.bookmarks.Update(msg)
@@ -127,6 +296,16 @@ func (v *View) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		v.bookmarks, viewCmd = v.handleList(msg)
 	default:
+		if v.reading != nil {
+			v.reader, viewCmd = v.reader.Update(msg)
+			break
+		}
+
+		if v.browsing != nil {
+			v.snapshots, viewCmd = v.snapshots.Update(msg)
+			break
+		}
+
 		v.input, inputCmd = v.input.Update(msg)
 		v.bookmarks, viewCmd = v.bookmarks.Update(msg)
 	}
@@ -134,22 +313,74 @@ func (v *View) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, tea.Batch(inputCmd, viewCmd)
 }
 
+// handleInput edits the title of the selected bookmark. Cancel
+// leaves the previous title in place and Confirm saves the new one.
 func (v *View) handleInput(msg tea.KeyMsg) (textinput.Model, tea.Cmd) {
-	if key.Matches(msg, quitKey) || key.Matches(msg, confirmKey) {
+	if key.Matches(msg, v.keys.Cancel) {
+		v.input.Reset()
+		v.input.Blur()
+		return v.input, tea.ClearScreen
+	}
+
+	if key.Matches(msg, v.keys.Confirm) {
+		var cmd tea.Cmd
 		item := v.bookmarks.SelectedItem().(*model.Bookmark)
 		if v.input.Value() != item.Title() {
 			item.Update(v.input.Value())
 			v.dirty = true
+			cmd = v.resort()
 		}
 
 		v.input.Reset()
 		v.input.Blur()
-		return v.input, tea.ClearScreen
+		return v.input, tea.Batch(cmd, tea.ClearScreen)
 	}
 
 	return v.input.Update(msg)
 }
 
+func (v *View) handleReader(msg tea.KeyMsg) (viewport.Model, tea.Cmd) {
+	if key.Matches(msg, v.bookmarks.KeyMap.ForceQuit) {
+		return v.reader, tea.Quit
+	}
+
+	if key.Matches(msg, v.keys.Back) {
+		v.reading = nil
+		return v.reader, tea.ClearScreen
+	}
+
+	return v.reader.Update(msg)
+}
+
+func (v *View) handleSnapshots(msg tea.KeyMsg) (list.Model, tea.Cmd) {
+	idx := v.snapshots.Index()
+	items := v.snapshots.Items()
+
+	switch {
+	case key.Matches(msg, v.keys.Back):
+		v.browsing = nil
+		return v.snapshots, tea.ClearScreen
+	case key.Matches(msg, v.keys.Open) && idx < len(items):
+		return v.snapshots, v.read(v.browsing, items[idx].(snapshotItem).Snapshot)
+	case key.Matches(msg, v.keys.Mark) && idx < len(items):
+		return v.snapshots, v.mark(items[idx].(snapshotItem).Snapshot)
+	case key.Matches(msg, v.keys.Diff) && idx < len(items):
+		selected := items[idx].(snapshotItem).Snapshot
+		other, ok := v.compared(idx)
+		if !ok {
+			return v.snapshots, v.snapshots.NewStatusMessage(msgNoOlder)
+		}
+
+		if other.Time.After(selected.Time) {
+			return v.snapshots, v.diff(v.browsing, selected, other)
+		}
+
+		return v.snapshots, v.diff(v.browsing, other, selected)
+	}
+
+	return v.snapshots.Update(msg)
+}
+
 func (v *View) handleList(msg tea.KeyMsg) (list.Model, tea.Cmd) {
 	item, ok := v.bookmarks.SelectedItem().(*model.Bookmark)
 	if !ok {
@@ -157,28 +388,59 @@ func (v *View) handleList(msg tea.KeyMsg) (list.Model, tea.Cmd) {
 	}
 
 	switch {
-	case key.Matches(msg, archiveKey):
-		_ = open("file://" + config.ArchiveFilePath(item.Id()))
-	case key.Matches(msg, confirmKey):
-		_ = open(item.URL())
-	case key.Matches(msg, delKey):
+	case key.Matches(msg, v.keys.Archive):
+		latest, ok := archive.Latest(item.Id())
+		if !ok {
+			return v.bookmarks, v.bookmarks.NewStatusMessage(fmt.Sprintf(msgNoArchive, item.Title()))
+		}
+
+		return v.bookmarks, v.read(item, latest)
+	case key.Matches(msg, v.keys.OpenArchive):
+		latest, ok := archive.Latest(item.Id())
+		if !ok {
+			return v.bookmarks, v.bookmarks.NewStatusMessage(fmt.Sprintf(msgNoArchive, item.Title()))
+		}
+
+		return v.bookmarks, v.open("file://"+latest.Path, item.Title())
+	case key.Matches(msg, v.keys.Snapshots):
+		return v.bookmarks, v.browse(item)
+	case key.Matches(msg, v.keys.Preview):
+		v.preview = !v.preview
+		v.bookmarks.SetSize(v.listWidth(), v.height)
+		return v.bookmarks, nil
+	case key.Matches(msg, v.keys.Open):
+		v.history.Touch(item.Id())
+		return v.bookmarks, v.open(item.URL(), item.Title())
+	case key.Matches(msg, v.keys.Delete):
 		var cmd tea.Cmd
 		v.actions = append(v.actions, action{
 			Operation: Delete,
-			Target:    v.bookmarks.SelectedItem().(*model.Bookmark).Id(),
+			Target:    item.Id(),
 		})
 
-		items := slices.DeleteFunc(v.bookmarks.Items(), func(item list.Item) bool {
-			return item == v.bookmarks.SelectedItem()
+		size := len(v.order)
+		v.order = slices.DeleteFunc(v.order, func(bk *model.Bookmark) bool {
+			return bk == item
 		})
 
-		if len(items) != len(v.bookmarks.Items()) {
+		if len(v.order) != size {
 			v.dirty = true
-			cmd = tea.Batch(v.bookmarks.SetItems(items), v.bookmarks.NewStatusMessage(fmt.Sprintf(msgStatus, item.Title())))
+			cmd = tea.Batch(v.bookmarks.SetItems(v.items()), v.bookmarks.NewStatusMessage(fmt.Sprintf(msgStatus, item.Title())))
 		}
 
 		return v.bookmarks, cmd
-	case key.Matches(msg, renameKey):
+	case key.Matches(msg, v.keys.MoveUp):
+		return v.bookmarks, v.move(-1)
+	case key.Matches(msg, v.keys.MoveDown):
+		return v.bookmarks, v.move(1)
+	case key.Matches(msg, v.keys.Sort):
+		v.sort = v.sort.next()
+		return v.bookmarks, tea.Batch(v.resort(), v.bookmarks.NewStatusMessage(fmt.Sprintf(msgSorted, v.sort)))
+	case key.Matches(msg, v.keys.Yank):
+		return v.bookmarks, v.copy(item.URL())
+	case key.Matches(msg, v.keys.YankLink):
+		return v.bookmarks, v.copy(markdownLink(item))
+	case key.Matches(msg, v.keys.Rename):
 		v.input.SetValue(item.Title())
 		v.input.Focus()
 		return v.bookmarks, textinput.Blink
@@ -187,20 +449,214 @@ func (v *View) handleList(msg tea.KeyMsg) (list.Model, tea.Cmd) {
 	return v.bookmarks.Update(msg)
 }
 
-func open(url string) error {
-	var cmd string
-	var args []string
+// move swaps the selected bookmark with its neighbour at delta distance.
+// Only allowed in manual mode without a filter so that the list index
+// matches the persisted order.
+func (v *View) move(delta int) tea.Cmd {
+	if v.sort != Manual {
+		return v.bookmarks.NewStatusMessage(msgManualOnly)
+	}
 
-	switch runtime.GOOS {
-	case "windows":
-		cmd = "cmd"
-		args = []string{"/c", "start"}
-	case "darwin":
-		cmd = "open"
-	default:
-		cmd = "xdg-open"
+	if v.bookmarks.FilterState() != list.Unfiltered {
+		return v.bookmarks.NewStatusMessage(msgFiltered)
+	}
+
+	curr := v.bookmarks.Index()
+	next := curr + delta
+	if next < 0 || next >= len(v.order) {
+		return nil
+	}
+
+	v.order[curr], v.order[next] = v.order[next], v.order[curr]
+	v.dirty = true
+
+	cmd := v.bookmarks.SetItems(v.items())
+	v.bookmarks.Select(next)
+	return cmd
+}
+
+// open starts the opener without waiting for it and reports failures
+// to start as status messages. Terminal openers take over the screen.
+func (v *View) open(rawURL string, title string) tea.Cmd {
+	cmd, err := v.opener.Command(rawURL, title)
+	if err != nil {
+		return v.bookmarks.NewStatusMessage(fmt.Sprintf(msgOpenFailed, err))
+	}
+
+	if v.opener.Terminal() {
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return openedMsg{err: err}
+		})
+	}
+
+	return func() tea.Msg {
+		return openedMsg{err: opener.Start(cmd)}
+	}
+}
+
+// browse lists the archive snapshots of item, newest first.
+func (v *View) browse(item *model.Bookmark) tea.Cmd {
+	snapshots, err := archive.Snapshots(item.Id())
+	if err != nil {
+		return v.bookmarks.NewStatusMessage(fmt.Sprintf(msgOpenFailed, err))
+	}
+
+	if len(snapshots) == 0 {
+		return v.bookmarks.NewStatusMessage(fmt.Sprintf(msgNoArchive, item.Title()))
+	}
+
+	v.browsing = item
+	v.base = ""
+	v.snapshots.Title = fmt.Sprintf(msgSnapshots, item.Title())
+	v.snapshots.Select(0)
+	return tea.Batch(v.snapshots.SetItems(snapshotItems(snapshots, v.base)), tea.ClearScreen)
+}
+
+// mark makes snapshot the base the others are compared with,
+// or clears the base if snapshot already is.
+func (v *View) mark(snapshot archive.Snapshot) tea.Cmd {
+	status := fmt.Sprintf(msgMarked, snapshot.Time.Local().Format(snapshotLayout))
+	if v.base == snapshot.Path {
+		v.base, status = "", msgUnmarked
+	} else {
+		v.base = snapshot.Path
+	}
+
+	var snapshots []archive.Snapshot
+	for _, item := range v.snapshots.Items() {
+		snapshots = append(snapshots, item.(snapshotItem).Snapshot)
+	}
+
+	return tea.Batch(v.snapshots.SetItems(snapshotItems(snapshots, v.base)), v.snapshots.NewStatusMessage(status))
+}
+
+// compared returns the snapshot the one at idx is compared with: the
+// marked base or, if there is none or it is the same, the previous one.
+func (v *View) compared(idx int) (archive.Snapshot, bool) {
+	items := v.snapshots.Items()
+	for i, item := range items {
+		if s := item.(snapshotItem); s.base && i != idx {
+			return s.Snapshot, true
+		}
+	}
+
+	if idx+1 >= len(items) {
+		return archive.Snapshot{}, false
+	}
+
+	return items[idx+1].(snapshotItem).Snapshot, true
+}
+
+// read renders snapshot into the reader viewport and switches the view to it.
+// Snapshots that are neither pages nor text e.g. PDFs are handed to the opener.
+func (v *View) read(item *model.Bookmark, snapshot archive.Snapshot) tea.Cmd {
+	if !snapshot.HTML() && !snapshot.Text() {
+		return v.open("file://"+snapshot.Path, item.Title())
 	}
 
-	args = append(args, url)
-	return exec.Command(cmd, args...).Start()
+	return v.show(item, item.URL()+" • "+snapshot.Time.Local().Format(snapshotLayout), func(width int) (string, error) {
+		raw, err := os.ReadFile(snapshot.Path)
+		if err != nil {
+			return "", err
+		}
+
+		if snapshot.Text() {
+			return wordwrap.String(string(raw), width), nil
+		}
+
+		return reader.Render(bytes.NewReader(archive.Content(raw)), width)
+	})
+}
+
+// diff shows the text changes between the older and newer snapshot in the reader.
+func (v *View) diff(item *model.Bookmark, older, newer archive.Snapshot) tea.Cmd {
+	lines, err := archive.Diff(older, newer)
+	if err != nil {
+		return v.snapshots.NewStatusMessage(fmt.Sprintf(msgOpenFailed, err))
+	}
+
+	caption := fmt.Sprintf("%s • %s → %s", item.URL(), older.Time.Local().Format(snapshotLayout), newer.Time.Local().Format(snapshotLayout))
+	return v.show(item, caption, func(width int) (string, error) {
+		return renderDiff(lines, width), nil
+	})
+}
+
+func (v *View) show(item *model.Bookmark, caption string, content func(width int) (string, error)) tea.Cmd {
+	text, err := content(min(v.width, readerWidth))
+	if err != nil {
+		if v.browsing != nil {
+			return v.snapshots.NewStatusMessage(fmt.Sprintf(msgOpenFailed, err))
+		}
+
+		return v.bookmarks.NewStatusMessage(fmt.Sprintf(msgOpenFailed, err))
+	}
+
+	v.reading = item
+	v.caption = caption
+	v.content = content
+	v.reader.SetContent(text)
+	v.reader.GotoTop()
+	return tea.ClearScreen
+}
+
+// resizeReader fits the reader below its header and above its footer and
+// re-renders the open archive and previews for the new width.
+func (v *View) resizeReader() {
+	v.reader.Width = v.width
+	v.reader.Height = max(v.height-readerChrome, 0)
+	clear(v.previews)
+
+	if v.reading == nil {
+		return
+	}
+
+	text, err := v.content(min(v.width, readerWidth))
+	if err == nil {
+		v.reader.SetContent(text)
+	}
+}
+
+// listWidth is the width of the list leaving room for the preview pane.
+func (v *View) listWidth() int {
+	if v.preview {
+		return v.width / 2
+	}
+
+	return v.width
+}
+
+func (v *View) copy(text string) tea.Cmd {
+	err := copyToClipboard(text)
+	if err != nil {
+		return v.bookmarks.NewStatusMessage(fmt.Sprintf(msgCopyFailed, err))
+	}
+
+	return v.bookmarks.NewStatusMessage(fmt.Sprintf(msgCopied, text))
+}
+
+// resort re-applies the current sort mode and keeps the selection
+// on the same bookmark.
+func (v *View) resort() tea.Cmd {
+	selected := v.bookmarks.SelectedItem()
+	items := v.items()
+	cmd := v.bookmarks.SetItems(items)
+
+	if v.bookmarks.FilterState() == list.Unfiltered {
+		if idx := slices.Index(items, selected); idx != -1 {
+			v.bookmarks.Select(idx)
+		}
+	}
+
+	return cmd
+}
+
+func (v *View) items() []list.Item {
+	sorted := sortBookmarks(v.order, v.sort, v.history)
+	res := make([]list.Item, len(sorted))
+
+	for i, bk := range sorted {
+		res[i] = bk
+	}
+
+	return res
 }
@@ -11,11 +11,11 @@ import (
 )
 
 // Confirm is a wrapper function for Confirmer that uses os.Stdin for input,
-// os.Stdout for output and a no operation Confirmer.Renderer.
+// os.Stdout for output and style.Confirm as Confirmer.Renderer.
 func Confirm(prompt string) bool {
 	return Confirmer{
 		MaxRetries: 3,
-		Renderer:   style.Nop,
+		Renderer:   style.Confirm,
 	}.Confirm(prompt, os.Stdin, os.Stdout)
 }
 
@@ -57,3 +57,46 @@ func (c Confirmer) Confirm(prompt string, in io.Reader, out io.Writer) bool {
 	_, _ = fmt.Fprintln(out, c.Renderer("Exceeded retry count. Aborting..."))
 	return false
 }
+
+// Choose is a wrapper function for Confirmer.Choose that uses os.Stdin for
+// input, os.Stdout for output and style.Confirm as Confirmer.Renderer.
+func Choose(prompt string, choices ...string) int {
+	return Confirmer{
+		MaxRetries: 3,
+		Renderer:   style.Confirm,
+	}.Choose(prompt, choices, os.Stdin, os.Stdout)
+}
+
+// Choose shows the user via out parameter a prompt listing choices and returns the index of
+// the one read via the in parameter, matched either in full or by its first letter.
+//
+// Returns -1 if the input cannot be read or the number of retries exceeded MaxRetries.
+func (c Confirmer) Choose(prompt string, choices []string, in io.Reader, out io.Writer) int {
+	reader := bufio.NewReader(in)
+	hints := make([]string, len(choices))
+	for i, choice := range choices {
+		hints[i] = fmt.Sprintf("(%c)%s", choice[0], choice[1:])
+	}
+
+	for retries := 0; retries < c.MaxRetries; retries++ {
+		_, err := fmt.Fprint(out, c.Renderer(fmt.Sprintf("%s [%s]: ", prompt, strings.Join(hints, "/"))))
+		if err != nil {
+			return -1
+		}
+
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return -1
+		}
+
+		response = strings.ToLower(strings.TrimSpace(response))
+		for i, choice := range choices {
+			if response != "" && (response == choice || response == choice[:1]) {
+				return i
+			}
+		}
+	}
+
+	_, _ = fmt.Fprintln(out, c.Renderer("Exceeded retry count. Aborting..."))
+	return -1
+}
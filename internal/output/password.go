@@ -0,0 +1,31 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"golang.org/x/term"
+)
+
+// Password shows prompt and reads a secret from os.Stdin without echoing it
+// back when attached to a terminal. Otherwise, it reads a single line.
+func Password(prompt string) (string, error) {
+	_, err := fmt.Fprint(os.Stderr, style.Confirm(prompt))
+	if err != nil {
+		return "", err
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	secret, err := term.ReadPassword(fd)
+	_, _ = fmt.Fprintln(os.Stderr)
+
+	return string(secret), err
+}
@@ -0,0 +1,369 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/loghinalexandru/anchor/internal/output/bubbletea/style"
+	"github.com/muesli/reflow/wordwrap"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	minWidth = 20
+)
+
+// Render converts the HTML document from r into word wrapped terminal text
+// no wider than width. Links are underlined and numbered with their targets
+// listed at the end of the text.
+func Render(r io.Reader, width int) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+
+	rr := &renderer{width: max(width, minWidth)}
+	rr.walk(doc)
+	rr.flush()
+	rr.footnotes()
+
+	return rr.out.String(), nil
+}
+
+// Preview renders only the first n blocks of text, e.g. paragraphs
+// or headings, of the HTML document from r without the links list.
+func Preview(r io.Reader, width int, n int) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+
+	rr := &renderer{width: max(width, minWidth), limit: n}
+	rr.walk(doc)
+	rr.flush()
+
+	return rr.out.String(), nil
+}
+
+type list struct {
+	ordered bool
+	count   int
+}
+
+// renderer walks the HTML tree accumulating styled inline text for the current
+// block and writes it word wrapped to out every time a block element ends.
+type renderer struct {
+	width int
+	limit int
+	count int
+	done  bool
+
+	out     strings.Builder
+	line    strings.Builder
+	pending bool
+	marker  string
+	tight   bool
+	links   []string
+
+	lists   []list
+	rows    int
+	quote   int
+	pre     int
+	heading int
+	bold    int
+	italic  int
+	code    int
+	link    int
+}
+
+func (r *renderer) walk(n *html.Node) {
+	if r.done {
+		return
+	}
+
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+	case html.ElementNode:
+		r.element(n)
+	default:
+		r.children(n)
+	}
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+func (r *renderer) element(n *html.Node) {
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Template, atom.Svg, atom.Iframe, atom.Form, atom.Button:
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.flush()
+		r.heading = int(n.Data[1] - '0')
+		r.children(n)
+		r.flush()
+		r.heading = 0
+	case atom.Ul, atom.Ol:
+		r.flush()
+		r.lists = append(r.lists, list{ordered: n.DataAtom == atom.Ol})
+		r.children(n)
+		r.flush()
+		r.lists = r.lists[:len(r.lists)-1]
+	case atom.Li:
+		r.flush()
+		r.marker = r.itemMarker()
+		r.children(n)
+		r.flush()
+	case atom.Blockquote:
+		r.flush()
+		r.quote++
+		r.children(n)
+		r.flush()
+		r.quote--
+	case atom.Pre:
+		r.flush()
+		r.pre++
+		r.children(n)
+		r.pre--
+		r.flushCode()
+	case atom.Table:
+		r.flush()
+		r.rows++
+		r.children(n)
+		r.flush()
+		r.rows--
+	case atom.Tr:
+		r.flush()
+		r.children(n)
+		r.flush()
+	case atom.Td, atom.Th:
+		if r.line.Len() > 0 {
+			r.line.WriteString(style.Muted().Render(" │"))
+			r.pending = true
+		}
+
+		r.children(n)
+	case atom.Br:
+		if r.pre > 0 {
+			r.line.WriteString("\n")
+			break
+		}
+
+		r.line.WriteString("\n")
+		r.pending = false
+	case atom.Hr:
+		r.flush()
+		r.write(style.Muted().Render(strings.Repeat("─", r.width/2)), false)
+	case atom.A:
+		href := strings.TrimSpace(attr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			r.children(n)
+			break
+		}
+
+		r.links = append(r.links, href)
+		r.link++
+		r.children(n)
+		r.link--
+		r.line.WriteString(style.Muted().Render(fmt.Sprintf("[%d]", len(r.links))))
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.text(" ")
+			r.line.WriteString(style.Muted().Render("[image: " + alt + "]"))
+			r.pending = true
+		}
+	case atom.B, atom.Strong:
+		r.bold++
+		r.children(n)
+		r.bold--
+	case atom.I, atom.Em:
+		r.italic++
+		r.children(n)
+		r.italic--
+	case atom.Code, atom.Kbd, atom.Samp:
+		r.code++
+		r.children(n)
+		r.code--
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer, atom.Aside,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd, atom.Details, atom.Summary:
+		r.flush()
+		r.children(n)
+		r.flush()
+	default:
+		r.children(n)
+	}
+}
+
+// text appends data to the current block collapsing whitespace
+// unless inside a <pre> element. Words are styled one by one so
+// that wrapping never breaks a styled sequence.
+func (r *renderer) text(data string) {
+	if r.pre > 0 {
+		r.line.WriteString(data)
+		return
+	}
+
+	first, _ := utf8.DecodeRuneInString(data)
+	last, _ := utf8.DecodeLastRuneInString(data)
+	if unicode.IsSpace(first) {
+		r.pending = true
+	}
+
+	s := r.style()
+	for _, word := range strings.Fields(data) {
+		if r.pending && r.line.Len() > 0 {
+			r.line.WriteString(" ")
+		}
+
+		r.line.WriteString(s.Render(word))
+		r.pending = true
+	}
+
+	r.pending = unicode.IsSpace(last)
+}
+
+func (r *renderer) style() lipgloss.Style {
+	var s lipgloss.Style
+	switch {
+	case r.heading > 0:
+		s = style.Heading()
+	case r.link > 0:
+		s = style.Link()
+	case r.code > 0:
+		s = style.Code()
+	default:
+		s = lipgloss.NewStyle()
+	}
+
+	if r.bold > 0 {
+		s = s.Bold(true)
+	}
+
+	if r.italic > 0 {
+		s = s.Italic(true)
+	}
+
+	return s
+}
+
+// flush writes the current block word wrapped and
+// prefixed according to the enclosing quotes and lists.
+func (r *renderer) flush() {
+	text := strings.TrimSpace(r.line.String())
+	r.line.Reset()
+	r.pending = false
+
+	if text == "" {
+		return
+	}
+
+	if r.heading > 0 {
+		text = style.Heading().Render(strings.Repeat("#", r.heading)) + " " + text
+	}
+
+	quote := ""
+	if r.quote > 0 {
+		quote = style.Muted().Render(strings.Repeat("│ ", r.quote))
+	}
+
+	indent := strings.Repeat("  ", len(r.lists))
+	first, rest := quote+indent, quote+indent
+	if r.marker != "" {
+		first = quote + r.marker
+		rest = quote + strings.Repeat(" ", lipgloss.Width(r.marker))
+		r.marker = ""
+	}
+
+	lines := strings.Split(wordwrap.String(text, max(r.width-lipgloss.Width(first), minWidth)), "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] = first + lines[i]
+			continue
+		}
+
+		lines[i] = rest + lines[i]
+	}
+
+	r.write(strings.Join(lines, "\n"), len(r.lists) > 0 || r.rows > 0)
+}
+
+// flushCode writes the current block as is with every line indented.
+func (r *renderer) flushCode() {
+	text := strings.Trim(r.line.String(), "\n")
+	r.line.Reset()
+
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = "    " + style.Code().Render(l)
+	}
+
+	r.write(strings.Join(lines, "\n"), false)
+}
+
+// write appends a block separated by an empty line, or a single new
+// line if both this and the previous block are list items or rows.
+func (r *renderer) write(block string, tight bool) {
+	if r.out.Len() > 0 {
+		if tight && r.tight {
+			r.out.WriteString("\n")
+		} else {
+			r.out.WriteString("\n\n")
+		}
+	}
+
+	r.out.WriteString(block)
+	r.tight = tight
+	r.count++
+
+	if r.limit > 0 && r.count >= r.limit {
+		r.done = true
+	}
+}
+
+func (r *renderer) footnotes() {
+	if len(r.links) == 0 {
+		return
+	}
+
+	r.out.WriteString("\n\n" + style.Heading().Render("Links"))
+	for i, l := range r.links {
+		r.out.WriteString("\n" + style.Muted().Render(fmt.Sprintf("[%d] %s", i+1, l)))
+	}
+}
+
+func (r *renderer) itemMarker() string {
+	if len(r.lists) == 0 {
+		return "• "
+	}
+
+	top := &r.lists[len(r.lists)-1]
+	indent := strings.Repeat("  ", len(r.lists)-1)
+	if !top.ordered {
+		return indent + "• "
+	}
+
+	top.count++
+	return fmt.Sprintf("%s%d. ", indent, top.count)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
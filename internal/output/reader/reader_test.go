@@ -0,0 +1,62 @@
+package reader_test
+
+import (
+	"flag"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/loghinalexandru/anchor/internal/output/reader"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var ansiRegexp = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	input, err := os.Open("testdata/article.input")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	defer input.Close()
+
+	got, err := reader.Render(input, 60)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got = ansiRegexp.ReplaceAllString(got, "")
+	if *update {
+		_ = os.WriteFile("testdata/article.golden", []byte(got), 0o600)
+	}
+
+	want, err := os.ReadFile("testdata/article.golden")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if diff := cmp.Diff(got, string(want)); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	input := strings.NewReader("<h1>Title</h1><p>First paragraph.</p><p>Second paragraph.</p>")
+
+	got, err := reader.Preview(input, 80, 2)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	want := "# Title\n\nFirst paragraph."
+	if diff := cmp.Diff(ansiRegexp.ReplaceAllString(got, ""), want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
@@ -0,0 +1,290 @@
+package search
+
+import (
+	"cmp"
+	"encoding/gob"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BM25 parameters, see https://en.wikipedia.org/wiki/Okapi_BM25.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Doc is an indexed archive.
+type Doc struct {
+	// Path is the snapshot the terms were read from.
+	Path   string
+	Length int
+	Terms  map[string]int
+}
+
+// Result is a document matching a query.
+type Result struct {
+	Id    uuid.UUID
+	Path  string
+	Score float64
+}
+
+// Index is an inverted index of archived pages ranked with BM25. It is
+// read from path on first use and safe for concurrent use.
+type Index struct {
+	path string
+	once sync.Once
+	err  error
+
+	mu       sync.RWMutex
+	dirty    bool
+	docs     map[uuid.UUID]*Doc
+	postings map[string]map[uuid.UUID]int
+	total    int
+}
+
+// stored is the on disk format of Index.
+type stored struct {
+	Docs map[uuid.UUID]*Doc
+}
+
+func New(path string) *Index {
+	return &Index{
+		path:     path,
+		docs:     map[uuid.UUID]*Doc{},
+		postings: map[string]map[uuid.UUID]int{},
+	}
+}
+
+func (idx *Index) load() error {
+	idx.once.Do(func() {
+		fh, err := os.Open(idx.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+
+		if err != nil {
+			idx.err = err
+			return
+		}
+
+		defer fh.Close()
+
+		var s stored
+		err = gob.NewDecoder(fh).Decode(&s)
+		if err != nil {
+			// A corrupt index starts empty instead of failing and is
+			// overwritten on the next save. The archives are indexed
+			// again the next time grep reconciles the index.
+			idx.dirty = true
+			return
+		}
+
+		for id, doc := range s.Docs {
+			idx.add(id, doc)
+		}
+	})
+
+	return idx.err
+}
+
+// Add indexes text of the archive at path as the document of id,
+// replacing any previous version of it.
+func (idx *Index) Add(id uuid.UUID, path string, text string) error {
+	err := idx.load()
+	if err != nil {
+		return err
+	}
+
+	terms := Terms(text)
+	doc := &Doc{Path: path, Length: len(terms), Terms: map[string]int{}}
+	for _, t := range terms {
+		doc.Terms[t]++
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+	idx.add(id, doc)
+	idx.dirty = true
+
+	return nil
+}
+
+// Remove drops the document of id, if any.
+func (idx *Index) Remove(id uuid.UUID) error {
+	err := idx.load()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, ok := idx.docs[id]; ok {
+		idx.remove(id)
+		idx.dirty = true
+	}
+
+	return nil
+}
+
+// Reset drops every document.
+func (idx *Index) Reset() error {
+	err := idx.load()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = map[uuid.UUID]*Doc{}
+	idx.postings = map[string]map[uuid.UUID]int{}
+	idx.total = 0
+	idx.dirty = true
+
+	return nil
+}
+
+// Docs returns the path each document was indexed from.
+func (idx *Index) Docs() (map[uuid.UUID]string, error) {
+	err := idx.load()
+	if err != nil {
+		return nil, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	res := make(map[uuid.UUID]string, len(idx.docs))
+	for id, doc := range idx.docs {
+		res[id] = doc.Path
+	}
+
+	return res, nil
+}
+
+// Search returns the documents containing any term of query, best first.
+func (idx *Index) Search(query string) ([]Result, error) {
+	err := idx.load()
+	if err != nil {
+		return nil, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	if n == 0 {
+		return nil, nil
+	}
+
+	avg := float64(idx.total) / n
+	scores := map[uuid.UUID]float64{}
+
+	terms := Terms(query)
+	slices.Sort(terms)
+	for _, t := range slices.Compact(terms) {
+		postings := idx.postings[t]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for id, tf := range postings {
+			f := float64(tf)
+			length := float64(idx.docs[id].Length)
+			scores[id] += idf * f * (k1 + 1) / (f + k1*(1-b+b*length/avg))
+		}
+	}
+
+	res := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		res = append(res, Result{Id: id, Path: idx.docs[id].Path, Score: score})
+	}
+
+	slices.SortFunc(res, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Id.String(), b.Id.String())
+	})
+
+	return res, nil
+}
+
+// Save writes the index to its path if it changed since it was read.
+func (idx *Index) Save() (err error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.dirty {
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(idx.path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(idx.path), ".index-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	err = gob.NewEncoder(tmp).Encode(stored{Docs: idx.docs})
+	err = errors.Join(err, tmp.Close())
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), idx.path)
+	if err == nil {
+		idx.dirty = false
+	}
+
+	return err
+}
+
+func (idx *Index) add(id uuid.UUID, doc *Doc) {
+	idx.docs[id] = doc
+	idx.total += doc.Length
+
+	for t, tf := range doc.Terms {
+		if idx.postings[t] == nil {
+			idx.postings[t] = map[uuid.UUID]int{}
+		}
+
+		idx.postings[t][id] = tf
+	}
+}
+
+func (idx *Index) remove(id uuid.UUID) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for t := range doc.Terms {
+		delete(idx.postings[t], id)
+		if len(idx.postings[t]) == 0 {
+			delete(idx.postings, t)
+		}
+	}
+
+	idx.total -= doc.Length
+	delete(idx.docs, id)
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	spec, blog, other := uuid.New(), uuid.New(), uuid.New()
+	idx := New(filepath.Join(t.TempDir(), "index"))
+
+	docs := map[uuid.UUID]string{
+		spec:  "The Go programming language specification. Go programs are constructed from packages.",
+		blog:  "A blog post about running and programming in Go.",
+		other: "Recipes for a sunny afternoon.",
+	}
+
+	for id, text := range docs {
+		if err := idx.Add(id, id.String(), text); err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+	}
+
+	res, err := idx.Search("programs specifications")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	want := []uuid.UUID{spec, blog}
+	if got := resultIds(res); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	err = idx.Remove(spec)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	res, _ = idx.Search("specification")
+	if len(res) != 0 {
+		t.Errorf("expected no results after remove; got %v", res)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "index")
+	id := uuid.New()
+
+	idx := New(path)
+	_ = idx.Add(id, "snapshot.html", "Archived pages are searchable")
+	if err := idx.Save(); err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	loaded := New(path)
+	res, err := loaded.Search("archive")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	want := []uuid.UUID{id}
+	if got := resultIds(res); !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+
+	if res[0].Path != "snapshot.html" {
+		t.Errorf("unexpected path; got %q", res[0].Path)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	t.Parallel()
+
+	text := "one two three four five six seven eight nine ten Running eleven twelve thirteen fourteen fifteen"
+	mark := func(s string) string { return "[" + s + "]" }
+
+	got := Snippet(text, "runs", 6, mark)
+	want := "… nine ten [Running] eleven twelve thirteen …"
+	if got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	if got := Snippet(text, "missing", 6, mark); got != "" {
+		t.Errorf("expected empty snippet; got %q", got)
+	}
+
+	if got := Snippet("short (run) text", "run", 10, strings.ToUpper); got != "short (RUN) text" {
+		t.Errorf("unexpected snippet; got %q", got)
+	}
+}
+
+func resultIds(res []Result) []uuid.UUID {
+	ids := make([]uuid.UUID, len(res))
+	for i, r := range res {
+		ids[i] = r.Id
+	}
+
+	return ids
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Snippet returns about width words of text around the first word
+// matching a term of query with every matching word passed to mark.
+// Returns an empty string if no word matches.
+func Snippet(text string, query string, width int, mark func(string) string) string {
+	wanted := map[string]bool{}
+	for _, t := range Terms(query) {
+		wanted[t] = true
+	}
+
+	fields := strings.Fields(text)
+	first := -1
+	for i, f := range fields {
+		if matches(f, wanted) {
+			first = i
+			break
+		}
+	}
+
+	if first == -1 {
+		return ""
+	}
+
+	start := max(first-width/3, 0)
+	end := min(start+width, len(fields))
+	start = max(end-width, 0)
+
+	res := make([]string, 0, end-start+2)
+	if start > 0 {
+		res = append(res, "…")
+	}
+
+	for _, f := range fields[start:end] {
+		if matches(f, wanted) {
+			f = mark(f)
+		}
+
+		res = append(res, f)
+	}
+
+	if end < len(fields) {
+		res = append(res, "…")
+	}
+
+	return strings.Join(res, " ")
+}
+
+// matches reports whether any word within field e.g. "(go," is wanted.
+func matches(field string, wanted map[string]bool) bool {
+	for _, w := range strings.FieldsFunc(field, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if t, ok := term(w); ok && wanted[t] {
+			return true
+		}
+	}
+
+	return false
+}
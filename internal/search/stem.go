@@ -0,0 +1,242 @@
+package search
+
+import "strings"
+
+// stem reduces an English word in lower case to its stem
+// using the original Porter stemming algorithm.
+func stem(word string) string {
+	if len(word) <= 2 || strings.IndexFunc(word, func(r rune) bool { return r < 'a' || r > 'z' }) != -1 {
+		return word
+	}
+
+	w := []byte(word)
+	w = step1a(w)
+	w = step1b(w)
+	w = step1c(w)
+	w = step2(w)
+	w = step3(w)
+	w = step4(w)
+	w = step5(w)
+
+	return string(w)
+}
+
+// consonant reports whether w[i] is a consonant, where
+// y is a consonant only if it does not follow one.
+func consonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !consonant(w, i-1)
+	}
+
+	return true
+}
+
+// measure counts the vowel-consonant sequences of w.
+func measure(w []byte) int {
+	n, i := 0, 0
+	for i < len(w) && consonant(w, i) {
+		i++
+	}
+
+	for i < len(w) {
+		for i < len(w) && !consonant(w, i) {
+			i++
+		}
+
+		if i == len(w) {
+			break
+		}
+
+		for i < len(w) && consonant(w, i) {
+			i++
+		}
+
+		n++
+	}
+
+	return n
+}
+
+func hasVowel(w []byte) bool {
+	for i := range w {
+		if !consonant(w, i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func doubleConsonant(w []byte) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && consonant(w, n-1)
+}
+
+// cvc reports whether w ends with consonant-vowel-consonant
+// where the last consonant is not w, x or y.
+func cvc(w []byte) bool {
+	n := len(w)
+	if n < 3 || !consonant(w, n-3) || consonant(w, n-2) || !consonant(w, n-1) {
+		return false
+	}
+
+	c := w[n-1]
+	return c != 'w' && c != 'x' && c != 'y'
+}
+
+func hasSuffix(w []byte, s string) bool {
+	return strings.HasSuffix(string(w), s)
+}
+
+// replace swaps suffix for repl if the stem before it has a measure above m.
+func replace(w []byte, suffix, repl string, m int) ([]byte, bool) {
+	if !hasSuffix(w, suffix) {
+		return w, false
+	}
+
+	base := w[:len(w)-len(suffix)]
+	if measure(base) > m {
+		return append(base[:len(base):len(base)], repl...), true
+	}
+
+	return w, true
+}
+
+func step1a(w []byte) []byte {
+	switch {
+	case hasSuffix(w, "sses"), hasSuffix(w, "ies"):
+		return w[:len(w)-2]
+	case hasSuffix(w, "ss"):
+		return w
+	case hasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+
+	return w
+}
+
+func step1b(w []byte) []byte {
+	if hasSuffix(w, "eed") {
+		if measure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+
+		return w
+	}
+
+	var base []byte
+	switch {
+	case hasSuffix(w, "ed") && hasVowel(w[:len(w)-2]):
+		base = w[:len(w)-2]
+	case hasSuffix(w, "ing") && hasVowel(w[:len(w)-3]):
+		base = w[:len(w)-3]
+	default:
+		return w
+	}
+
+	switch {
+	case hasSuffix(base, "at"), hasSuffix(base, "bl"), hasSuffix(base, "iz"):
+		return append(base[:len(base):len(base)], 'e')
+	case doubleConsonant(base):
+		if c := base[len(base)-1]; c != 'l' && c != 's' && c != 'z' {
+			return base[:len(base)-1]
+		}
+	case measure(base) == 1 && cvc(base):
+		return append(base[:len(base):len(base)], 'e')
+	}
+
+	return base
+}
+
+func step1c(w []byte) []byte {
+	if hasSuffix(w, "y") && hasVowel(w[:len(w)-1]) {
+		return append(w[:len(w)-1:len(w)-1], 'i')
+	}
+
+	return w
+}
+
+var step2Suffixes = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"abli", "able"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+}
+
+var step3Suffixes = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+func step2(w []byte) []byte {
+	return replaceFirst(w, step2Suffixes)
+}
+
+func step3(w []byte) []byte {
+	return replaceFirst(w, step3Suffixes)
+}
+
+// replaceFirst applies the longest matching rule of suffixes with measure above 0.
+func replaceFirst(w []byte, suffixes [][2]string) []byte {
+	best := -1
+	for i, s := range suffixes {
+		if hasSuffix(w, s[0]) && (best == -1 || len(s[0]) > len(suffixes[best][0])) {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return w
+	}
+
+	res, _ := replace(w, suffixes[best][0], suffixes[best][1], 0)
+	return res
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement",
+	"ment", "ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func step4(w []byte) []byte {
+	best := ""
+	for _, s := range step4Suffixes {
+		if hasSuffix(w, s) && len(s) > len(best) {
+			best = s
+		}
+	}
+
+	if best == "" {
+		return w
+	}
+
+	base := w[:len(w)-len(best)]
+	if best == "ion" && (len(base) == 0 || (base[len(base)-1] != 's' && base[len(base)-1] != 't')) {
+		return w
+	}
+
+	if measure(base) > 1 {
+		return base
+	}
+
+	return w
+}
+
+func step5(w []byte) []byte {
+	if hasSuffix(w, "e") {
+		base := w[:len(w)-1]
+		if m := measure(base); m > 1 || (m == 1 && !cvc(base)) {
+			w = base
+		}
+	}
+
+	if measure(w) > 1 && doubleConsonant(w) && w[len(w)-1] == 'l' {
+		w = w[:len(w)-1]
+	}
+
+	return w
+}
@@ -0,0 +1,49 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	t.Parallel()
+
+	tsc := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"valenci":        "valenc",
+		"digitizer":      "digit",
+		"operator":       "oper",
+		"hopefulness":    "hope",
+		"electriciti":    "electr",
+		"revival":        "reviv",
+		"adjustment":     "adjust",
+		"adoption":       "adopt",
+		"probate":        "probat",
+		"controll":       "control",
+		"generalization": "gener",
+		"running":        "run",
+		"go":             "go",
+		"http2":          "http2",
+	}
+
+	for in, want := range tsc {
+		t.Run(in, func(t *testing.T) {
+			t.Parallel()
+
+			if got := stem(in); got != want {
+				t.Errorf("stem(%q); want %q, got %q", in, want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTokenLength drops long runs of characters e.g. hashes or base64.
+const maxTokenLength = 40
+
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a about above after again against all am an and any are as at be because
+		been before being below between both but by can did do does doing down during each few for from further
+		had has have having he her here hers herself him himself his how i if in into is it its itself just me
+		more most my myself no nor not now of off on once only or other our ours ourselves out over own same she
+		should so some such than that the their theirs them themselves then there these they this those through
+		to too under until up very was we were what when where which while who whom why will with you your yours
+		yourself yourselves`) {
+		stopwords[w] = true
+	}
+}
+
+// Terms splits text into lower case, stemmed terms
+// leaving out stop words and very long tokens.
+func Terms(text string) []string {
+	var res []string
+	for _, w := range words(text) {
+		if t, ok := term(w); ok {
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// term normalizes a single word to its indexed form.
+func term(word string) (string, bool) {
+	w := strings.ToLower(word)
+	if stopwords[w] || len(w) > maxTokenLength {
+		return "", false
+	}
+
+	return stem(w), true
+}
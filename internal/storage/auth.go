@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	stdTokenEnv = "ANCHOR_GIT_TOKEN"
+)
+
+var (
+	ErrInvalidAuthMode    = errors.New("invalid auth mode")
+	ErrMissingToken       = errors.New("missing token in environment")
+	ErrMissingKey         = errors.New("no private key found")
+	ErrMissingPassphrase  = errors.New("private key requires a passphrase")
+	ErrMissingCredentials = errors.New("credential helper returned no credentials")
+)
+
+// AuthMode selects how git storage authenticates against the remote.
+type AuthMode int
+
+const (
+	// AutoAuth infers the mode from the remote URL.
+	AutoAuth AuthMode = iota
+	AgentAuth
+	KeyAuth
+	TokenAuth
+	HelperAuth
+	NoAuth
+)
+
+var authModeNames = []string{"auto", "agent", "key", "token", "helper", "none"}
+
+func ParseAuthMode(s string) (AuthMode, error) {
+	idx := slices.Index(authModeNames, strings.ToLower(strings.TrimSpace(s)))
+	if idx == -1 {
+		return AutoAuth, fmt.Errorf("%q: %w", s, ErrInvalidAuthMode)
+	}
+
+	return AuthMode(idx), nil
+}
+
+func (m AuthMode) String() string {
+	return authModeNames[m]
+}
+
+// infer picks the auth mode for endpoint. SSH remotes prefer a running agent
+// over key files and HTTPS remotes prefer a token over the credential helper.
+// An inferred helper that yields no credentials falls back to no auth.
+func (storage *gitStorage) infer(endpoint *transport.Endpoint) AuthMode {
+	switch endpoint.Protocol {
+	case "ssh":
+		if os.Getenv("SSH_AUTH_SOCK") != "" {
+			return AgentAuth
+		}
+
+		return KeyAuth
+	case "http", "https":
+		if os.Getenv(storage.tokenEnv) != "" {
+			return TokenAuth
+		}
+
+		return HelperAuth
+	default:
+		return NoAuth
+	}
+}
+
+// authFor returns the auth method for rawURL. The result is reused
+// for the same URL so a passphrase is asked for at most once.
+func (storage *gitStorage) authFor(rawURL string) (transport.AuthMethod, error) {
+	if auth, ok := storage.auths[rawURL]; ok {
+		return auth, nil
+	}
+
+	endpoint, err := transport.NewEndpoint(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := storage.mode
+	if mode == AutoAuth {
+		mode = storage.infer(endpoint)
+	}
+
+	var auth transport.AuthMethod
+	switch mode {
+	case AgentAuth:
+		auth, err = ssh.NewSSHAgentAuth(cmp.Or(endpoint.User, storage.user))
+	case KeyAuth:
+		auth, err = storage.keyAuth(cmp.Or(endpoint.User, storage.user))
+	case TokenAuth:
+		auth, err = storage.tokenAuth()
+	case HelperAuth:
+		auth, err = helperAuth(endpoint)
+		if err != nil && storage.mode == AutoAuth {
+			auth, err = nil, nil
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s auth: %w", mode, err)
+	}
+
+	storage.auths[rawURL] = auth
+	return auth, nil
+}
+
+func (storage *gitStorage) keyAuth(user string) (transport.AuthMethod, error) {
+	path := expandHome(storage.key)
+	if path == "" {
+		path = defaultKey()
+		if path == "" {
+			return nil, ErrMissingKey
+		}
+	}
+
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var passphrase string
+	_, err = cryptossh.ParsePrivateKey(pem)
+	var missing *cryptossh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if storage.prompt == nil {
+			return nil, fmt.Errorf("%s: %w", path, ErrMissingPassphrase)
+		}
+
+		passphrase, err = storage.prompt(fmt.Sprintf("Passphrase for %s: ", path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ssh.NewPublicKeys(user, pem, passphrase)
+}
+
+func (storage *gitStorage) tokenAuth() (transport.AuthMethod, error) {
+	token := os.Getenv(storage.tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("%s: %w", storage.tokenEnv, ErrMissingToken)
+	}
+
+	return &http.BasicAuth{
+		Username: storage.user,
+		Password: token,
+	}, nil
+}
+
+// helperAuth asks the credential helpers configured for git,
+// see https://git-scm.com/docs/git-credential.
+func helperAuth(endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	host := endpoint.Host
+	if endpoint.Port != 0 {
+		host = fmt.Sprintf("%s:%d", host, endpoint.Port)
+	}
+
+	var in bytes.Buffer
+	fmt.Fprintf(&in, "protocol=%s\nhost=%s\n", endpoint.Protocol, host)
+
+	if endpoint.User != "" {
+		fmt.Fprintf(&in, "username=%s\n", endpoint.User)
+	}
+
+	fmt.Fprintf(&in, "path=%s\n\n", strings.TrimPrefix(endpoint.Path, "/"))
+
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = &in
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &http.BasicAuth{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "username":
+			auth.Username = value
+		case "password":
+			auth.Password = value
+		}
+	}
+
+	if auth.Password == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	return auth, nil
+}
+
+// expandHome replaces a leading "~/" with the home directory of the user
+// since paths from the settings file are not expanded by a shell.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, rest)
+}
+
+// defaultKey returns the first private key found
+// in the places ssh looks for them by default.
+func defaultKey() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		path := filepath.Join(home, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
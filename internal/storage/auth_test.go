@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+func TestParseAuthMode(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		in   string
+		want AuthMode
+		err  error
+	}{
+		{in: "auto", want: AutoAuth},
+		{in: " Key ", want: KeyAuth},
+		{in: "helper", want: HelperAuth},
+		{in: "none", want: NoAuth},
+		{in: "password", want: AutoAuth, err: ErrInvalidAuthMode},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseAuthMode(tc.in)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error; want %q, got %q", tc.err, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestInfer(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	t.Setenv(stdTokenEnv, "secret")
+
+	tcs := []struct {
+		url  string
+		want AuthMode
+	}{
+		{url: "git@github.com:loghinalexandru/anchor.git", want: KeyAuth},
+		{url: "ssh://git@github.com/loghinalexandru/anchor.git", want: KeyAuth},
+		{url: "https://github.com/loghinalexandru/anchor.git", want: TokenAuth},
+		{url: "file:///srv/anchor.git", want: NoAuth},
+		{url: "/srv/anchor.git", want: NoAuth},
+	}
+
+	storage := newGitStorage(t.TempDir())
+	for _, tc := range tcs {
+		endpoint, err := transport.NewEndpoint(tc.url)
+		if err != nil {
+			t.Fatalf("unexpected error; got %q", err)
+		}
+
+		if got := storage.infer(endpoint); got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.url, tc.want, got)
+		}
+	}
+}
+
+func TestAuthFor(t *testing.T) {
+	t.Setenv("CUSTOM_TOKEN", "secret")
+
+	storage := newGitStorage(t.TempDir(), WithTokenEnv("CUSTOM_TOKEN"), WithUser("anchor"))
+
+	auth, err := storage.authFor("/srv/anchor.git")
+	if err != nil || auth != nil {
+		t.Errorf("unexpected auth for local remote; got %v, %q", auth, err)
+	}
+
+	auth, err = storage.authFor("https://example.com/anchor.git")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	basic, ok := auth.(*http.BasicAuth)
+	if !ok || basic.Username != "anchor" || basic.Password != "secret" {
+		t.Errorf("unexpected auth; got %v", auth)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	storage = newGitStorage(t.TempDir(), WithTokenEnv("MISSING_TOKEN"))
+	auth, err = storage.authFor("https://example.com/anchor.git")
+	if err != nil || auth != nil {
+		t.Errorf("unexpected auth without credential helper; got %v, %q", auth, err)
+	}
+
+	storage = newGitStorage(t.TempDir(), WithAuth(TokenAuth), WithTokenEnv("MISSING_TOKEN"))
+	_, err = storage.authFor("https://example.com/anchor.git")
+	if !errors.Is(err, ErrMissingToken) {
+		t.Errorf("unexpected error; want %q, got %q", ErrMissingToken, err)
+	}
+
+	storage = newGitStorage(t.TempDir(), WithAuth(KeyAuth), WithKey(t.TempDir()+"/missing"))
+	_, err = storage.authFor("git@github.com:loghinalexandru/anchor.git")
+	if err == nil {
+		t.Error("missing expected error")
+	}
+}
+
+func TestKeyAuthHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	block, err := cryptossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	err = os.MkdirAll(filepath.Join(home, ".ssh"), 0o700)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	err = os.WriteFile(filepath.Join(home, ".ssh", "anchor_ed25519"), pem.EncodeToMemory(block), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	storage := newGitStorage(t.TempDir(), WithAuth(KeyAuth), WithKey("~/.ssh/anchor_ed25519"))
+	auth, err := storage.authFor("git@github.com:loghinalexandru/anchor.git")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if _, ok := auth.(*ssh.PublicKeys); !ok {
+		t.Errorf("unexpected auth; want public keys, got %T", auth)
+	}
+}
@@ -0,0 +1,337 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+var (
+	ErrLabelNotFound    = errors.New("label not found in revision")
+	ErrBookmarkNotFound = errors.New("bookmark not found in revision")
+	ErrBookmarkExists   = errors.New("bookmark already exists")
+	ErrNothingToRestore = errors.New("label is already the same as in revision")
+)
+
+// ChangeKind tells how a bookmark changed between two versions of a label.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Modified
+	// Reordered means bookmarks were only moved within the label.
+	Reordered
+	// Touched means the file changed without any bookmark changing
+	// e.g. only whitespace or a hidden file such as .gitignore.
+	Touched
+)
+
+// Change is a bookmark added, removed or modified in a label.
+// Old is nil for added bookmarks and New for removed ones,
+// both are nil if the label was only reordered or touched.
+// Key is the id of the bookmark or its URL if saved without one.
+type Change struct {
+	Label string
+	Key   string
+	Kind  ChangeKind
+	Old   *model.Bookmark
+	New   *model.Bookmark
+}
+
+// Revision is a commit with the bookmarks it changed.
+type Revision struct {
+	Hash    string
+	Author  string
+	When    time.Time
+	Message string
+	Changes []Change
+}
+
+// Log returns up to limit revisions of the current branch, newest first,
+// changing labels accepted by match. A nil match accepts every label.
+// Merge commits are compared against their first parent so they show what
+// the merge brought in.
+func (storage *gitStorage) Log(limit int, match func(label string) bool) ([]Revision, error) {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := repo.Log(&git.LogOptions{})
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Revision
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(res) == limit {
+			return storer.ErrStop
+		}
+
+		var parent *object.Commit
+		if c.NumParents() > 0 {
+			var err error
+			parent, err = c.Parent(0)
+			if err != nil {
+				return err
+			}
+		}
+
+		before, err := commitFiles(parent)
+		if err != nil {
+			return err
+		}
+
+		after, err := commitFiles(c)
+		if err != nil {
+			return err
+		}
+
+		changes, err := diffFiles(before, after, match)
+		if err != nil {
+			return err
+		}
+
+		if match != nil && len(changes) == 0 {
+			return nil
+		}
+
+		res = append(res, Revision{
+			Hash:    c.Hash.String(),
+			Author:  c.Author.Name,
+			When:    c.Author.When,
+			Message: c.Message,
+			Changes: changes,
+		})
+
+		return nil
+	})
+
+	return res, err
+}
+
+// RestoreLabel brings back the label file name as it was at rev and commits it.
+func (storage *gitStorage) RestoreLabel(rev string, name string) error {
+	repo, c, err := storage.revision(rev)
+	if err != nil {
+		return err
+	}
+
+	files, err := commitFiles(c)
+	if err != nil {
+		return err
+	}
+
+	content, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrLabelNotFound)
+	}
+
+	current, err := os.ReadFile(filepath.Join(storage.path, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err == nil && bytes.Equal(current, content) {
+		return fmt.Errorf("%s: %w", name, ErrNothingToRestore)
+	}
+
+	err = os.WriteFile(filepath.Join(storage.path, name), content, config.StdFileMode)
+	if err != nil {
+		return err
+	}
+
+	return storage.commitFile(repo, name, fmt.Sprintf("Restore %s from %s", name, c.Hash.String()[:7]))
+}
+
+// RestoreBookmark brings back the bookmark with id, or URL for bookmarks saved
+// without one, as it was at rev and commits it. The bookmark is appended to the
+// label it was found in, restricted to name if set. Returns the bookmark and
+// the label it was restored to.
+func (storage *gitStorage) RestoreBookmark(rev string, id string, name string) (*model.Bookmark, string, error) {
+	repo, c, err := storage.revision(rev)
+	if err != nil {
+		return nil, "", err
+	}
+
+	files, err := commitFiles(c)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var found *entry
+	for _, label := range slices.Sorted(maps.Keys(files)) {
+		if strings.HasPrefix(label, ".") || name != "" && label != name {
+			continue
+		}
+
+		ee, err := entries(label, files[label])
+		if err != nil {
+			return nil, "", err
+		}
+
+		if i := slices.IndexFunc(ee, func(e entry) bool { return e.key == id }); i != -1 {
+			found, name = &ee[i], label
+			break
+		}
+	}
+
+	if found == nil {
+		return nil, "", fmt.Errorf("%s: %w", id, ErrBookmarkNotFound)
+	}
+
+	path := filepath.Join(storage.path, name)
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, "", err
+	}
+
+	ee, err := entries(name, current)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if slices.ContainsFunc(ee, func(e entry) bool { return e.key == id }) {
+		return nil, "", fmt.Errorf("%s: %w", found.bk.Title(), ErrBookmarkExists)
+	}
+
+	fh, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, config.StdFileMode)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, err = fh.WriteString(found.line + "\n")
+	err = errors.Join(err, fh.Close())
+	if err != nil {
+		return nil, "", err
+	}
+
+	msg := fmt.Sprintf("Restore %q to %s from %s", found.bk.Title(), name, c.Hash.String()[:7])
+	return found.bk, name, storage.commitFile(repo, name, msg)
+}
+
+// revision returns the commit rev refers to e.g. a hash, a prefix of one or HEAD~2.
+func (storage *gitStorage) revision(rev string) (*git.Repository, *object.Commit, error) {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", rev, err)
+	}
+
+	c, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return repo, c, nil
+}
+
+// commitFile commits only the file name leaving other changes uncommitted.
+func (storage *gitStorage) commitFile(repo *git.Repository, name string, msg string) error {
+	author, err := storage.signature(repo)
+	if err != nil {
+		return err
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	_, err = tree.Add(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = tree.Commit(msg, &git.CommitOptions{Author: author})
+	return err
+}
+
+// diffFiles returns the changes of every label accepted by match, sorted by
+// label. Hidden files e.g. .gitignore are not labels and are skipped.
+func diffFiles(before, after map[string][]byte, match func(label string) bool) ([]Change, error) {
+	names := map[string]bool{}
+	for _, files := range []map[string][]byte{before, after} {
+		for name := range files {
+			names[name] = true
+		}
+	}
+
+	var res []Change
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		if strings.HasPrefix(name, ".") || match != nil && !match(name) || bytes.Equal(before[name], after[name]) {
+			continue
+		}
+
+		changes, err := diffLabel(name, before[name], after[name])
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, changes...)
+	}
+
+	return res, nil
+}
+
+// diffLabel returns the bookmarks added or modified in the order of after
+// followed by the bookmarks removed in the order of before. If bookmarks
+// were only moved within the label a single Reordered change is returned.
+func diffLabel(name string, before, after []byte) ([]Change, error) {
+	old, err := entries(name, before)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := entries(name, after)
+	if err != nil {
+		return nil, err
+	}
+
+	oldIdx, curIdx := index(old), index(cur)
+
+	var res []Change
+	for _, e := range cur {
+		prev, ok := oldIdx[e.key]
+		switch {
+		case !ok:
+			res = append(res, Change{Label: name, Key: e.key, Kind: Added, New: e.bk})
+		case prev.line != e.line:
+			res = append(res, Change{Label: name, Key: e.key, Kind: Modified, Old: prev.bk, New: e.bk})
+		}
+	}
+
+	for _, e := range old {
+		if _, ok := curIdx[e.key]; !ok {
+			res = append(res, Change{Label: name, Key: e.key, Kind: Removed, Old: e.bk})
+		}
+	}
+
+	if len(res) == 0 && !slices.Equal(keys(old), keys(cur)) {
+		res = append(res, Change{Label: name, Kind: Reordered})
+	}
+
+	return res, nil
+}
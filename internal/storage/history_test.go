@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffLabel(t *testing.T) {
+	t.Parallel()
+
+	before := lines(line("A", idA), line("B", idB), line("C", idC))
+	after := lines(line("C", idC), line("A2", idA), line("D", idD))
+
+	got, err := diffLabel("root", before, after)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	type change struct {
+		Kind  ChangeKind
+		Key   string
+		Title string
+	}
+
+	want := []change{
+		{Kind: Modified, Key: idA, Title: "A2"},
+		{Kind: Added, Key: idD, Title: "D"},
+		{Kind: Removed, Key: idB, Title: "B"},
+	}
+
+	var res []change
+	for _, c := range got {
+		bk := c.New
+		if bk == nil {
+			bk = c.Old
+		}
+
+		res = append(res, change{Kind: c.Kind, Key: c.Key, Title: bk.Title()})
+	}
+
+	if diff := cmp.Diff(res, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repo := seed(t, filepath.Join(dir, "remote.git"), dir)
+	commit(t, repo, dir, "root", lines(line("A", idA)))
+	commit(t, repo, dir, ".gitignore", []byte("*.tmp\n"))
+	commit(t, repo, dir, "go", lines(line("B", idB)))
+	commit(t, repo, dir, "root", lines(line("A2", idA)))
+
+	storage := newGitStorage(dir)
+	all, err := storage.Log(10, nil)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if len(all) != 4 {
+		t.Fatalf("unexpected number of revisions; want 4, got %d", len(all))
+	}
+
+	if c := all[0].Changes; len(c) != 1 || c[0].Kind != Modified || c[0].Old.Title() != "A" || c[0].New.Title() != "A2" {
+		t.Errorf("unexpected changes in latest revision; got %+v", c)
+	}
+
+	root, err := storage.Log(10, func(label string) bool { return label == "root" })
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if len(root) != 2 || root[1].Hash != all[3].Hash {
+		t.Errorf("unexpected revisions; want only the ones changing root, got %+v", root)
+	}
+
+	latest, err := storage.Log(1, func(label string) bool { return label == "root" })
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if len(latest) != 1 || latest[0].Hash != all[0].Hash {
+		t.Errorf("unexpected revisions; want only the latest one changing root, got %+v", latest)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repo := seed(t, filepath.Join(dir, "remote.git"), dir)
+	commit(t, repo, dir, "root", lines(line("A", idA), line("B", idB)))
+	commit(t, repo, dir, "root", lines(line("C", idC)))
+
+	storage := newGitStorage(dir)
+	bk, name, err := storage.RestoreBookmark("HEAD~1", idB, "")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if bk.Title() != "B" || name != "root" {
+		t.Errorf("unexpected restored bookmark; got %q in %q", bk.Title(), name)
+	}
+
+	_, _, err = storage.RestoreBookmark("HEAD~2", idB, "")
+	if !errors.Is(err, ErrBookmarkExists) {
+		t.Errorf("unexpected error; want %q, got %q", ErrBookmarkExists, err)
+	}
+
+	_, _, err = storage.RestoreBookmark("HEAD", idD, "")
+	if !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("unexpected error; want %q, got %q", ErrBookmarkNotFound, err)
+	}
+
+	assertContent(t, dir, "root", lines(line("C", idC), line("B", idB)))
+
+	err = storage.RestoreLabel("HEAD~2", "root")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	assertContent(t, dir, "root", lines(line("A", idA), line("B", idB)))
+
+	err = storage.RestoreLabel("HEAD", "root")
+	if !errors.Is(err, ErrNothingToRestore) {
+		t.Errorf("unexpected error; want %q, got %q", ErrNothingToRestore, err)
+	}
+
+	err = storage.RestoreLabel("HEAD", "missing")
+	if !errors.Is(err, ErrLabelNotFound) {
+		t.Errorf("unexpected error; want %q, got %q", ErrLabelNotFound, err)
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	status, err := tree.Status()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if !status.IsClean() {
+		t.Errorf("unexpected uncommitted restores; got %s", status)
+	}
+}
+
+func assertContent(t *testing.T, dir string, name string, want []byte) {
+	t.Helper()
+
+	got, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if diff := cmp.Diff(string(got), string(want)); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
@@ -26,8 +26,8 @@ func (l *localStorage) Init(_ ...string) error {
 	return nil
 }
 
-func (*localStorage) Diff() (string, error) {
-	return "", errors.New("running on local storage type, command has no effect")
+func (*localStorage) Diff() ([]Change, error) {
+	return nil, errors.New("running on local storage type, command has no effect")
 }
 
 func (*localStorage) Store(_ string) error {
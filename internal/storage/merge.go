@@ -0,0 +1,542 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/loghinalexandru/anchor/internal/config"
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+var (
+	ErrMergeConflict = errors.New("conflicting changes")
+)
+
+// Conflict is a bookmark edited differently on both sides of a merge.
+type Conflict struct {
+	Label  string
+	Base   *model.Bookmark
+	Ours   *model.Bookmark
+	Theirs *model.Bookmark
+}
+
+// Resolver picks the version of a conflicting bookmark to keep.
+type Resolver func(Conflict) (*model.Bookmark, error)
+
+// entry is a parsed line of a label file.
+type entry struct {
+	key  string
+	line string
+	bk   *model.Bookmark
+}
+
+// merge3 merges every file changed on either side since base. A nil
+// content means the file does not exist on that side. Files that end up
+// without bookmarks are dropped if either side removed them.
+func merge3(base, ours, theirs map[string][]byte, resolve Resolver) (map[string][]byte, error) {
+	names := map[string]bool{}
+	for _, files := range []map[string][]byte{base, ours, theirs} {
+		for name := range files {
+			names[name] = true
+		}
+	}
+
+	res := map[string][]byte{}
+	for name := range names {
+		b, o, t := base[name], ours[name], theirs[name]
+		switch {
+		case same(o, t), same(t, b):
+			if o != nil {
+				res[name] = o
+			}
+
+			continue
+		case same(o, b):
+			if t != nil {
+				res[name] = t
+			}
+
+			continue
+		}
+
+		merged, err := mergeLabel(name, b, o, t, resolve)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(merged) == 0 && (o == nil || t == nil) {
+			continue
+		}
+
+		res[name] = merged
+	}
+
+	return res, nil
+}
+
+// mergeLabel merges a label file as a list of bookmarks keyed by id: additions
+// of both sides are kept, deletions and edits are propagated and bookmarks
+// edited differently on both sides are handed to resolve. A deletion wins
+// over an edit of the same bookmark on the other side. The order of the
+// side that reordered the bookmarks is kept, additions of the other side
+// follow the bookmark they were added after.
+func mergeLabel(name string, base, ours, theirs []byte, resolve Resolver) ([]byte, error) {
+	b, err := entries(name, base)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := entries(name, ours)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := entries(name, theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	baseIdx, oursIdx, theirsIdx := index(b), index(o), index(t)
+
+	lead, other, leadIdx := o, t, oursIdx
+	if slices.Equal(keys(common(o, baseIdx)), keys(common(b, oursIdx))) {
+		lead, other, leadIdx = t, o, theirsIdx
+	}
+
+	inBase := func(e entry) bool {
+		_, ok := baseIdx[e.key]
+		return ok
+	}
+
+	var res []entry
+	for _, e := range lead {
+		be, inBase := baseIdx[e.key]
+		oe, inOurs := oursIdx[e.key]
+		te, inTheirs := theirsIdx[e.key]
+
+		switch {
+		case !inBase:
+			// Added on the lead side, or on both.
+			res = append(res, e)
+		case !inOurs || !inTheirs:
+			// Removed on one side.
+		case oe.line == be.line:
+			res = append(res, te)
+		case te.line == be.line || te.line == oe.line:
+			res = append(res, oe)
+		default:
+			if resolve == nil {
+				return nil, fmt.Errorf("%s: %q: %w", name, oe.bk.Title(), ErrMergeConflict)
+			}
+
+			bk, err := resolve(Conflict{Label: name, Base: be.bk, Ours: oe.bk, Theirs: te.bk})
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, entry{key: e.key, line: strings.TrimSuffix(bk.String(), "\n"), bk: bk})
+		}
+	}
+
+	// Insert the additions of the other side after the closest bookmark
+	// preceding them that made it into the result and after the additions
+	// of the lead side at the same place.
+	for i, e := range other {
+		if inBase(e) {
+			continue
+		}
+
+		if _, ok := leadIdx[e.key]; ok {
+			continue
+		}
+
+		pos := 0
+		for j := i - 1; j >= 0; j-- {
+			if k := slices.IndexFunc(res, func(r entry) bool { return r.key == other[j].key }); k != -1 {
+				pos = k + 1
+				break
+			}
+		}
+
+		for pos < len(res) && !inBase(res[pos]) {
+			pos++
+		}
+
+		res = slices.Insert(res, pos, e)
+	}
+
+	var buf bytes.Buffer
+	for _, e := range res {
+		buf.WriteString(e.line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// same reports whether a and b have the same content and both exist or not.
+func same(a, b []byte) bool {
+	return (a == nil) == (b == nil) && bytes.Equal(a, b)
+}
+
+func entries(name string, content []byte) ([]entry, error) {
+	var res []entry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		bk, err := model.BookmarkLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		res = append(res, entry{key: key(line, bk), line: line, bk: bk})
+	}
+
+	return res, scanner.Err()
+}
+
+// key identifies the bookmark on line by its id or, for lines written
+// before bookmarks had one and got a random id on parsing, by its URL.
+func key(line string, bk *model.Bookmark) string {
+	id := bk.Id().String()
+	if strings.Contains(line, strconv.Quote(id)) {
+		return id
+	}
+
+	return bk.URL()
+}
+
+func index(ee []entry) map[string]entry {
+	res := make(map[string]entry, len(ee))
+	for _, e := range ee {
+		res[e.key] = e
+	}
+
+	return res
+}
+
+// common returns the entries of ee also present in idx.
+func common(ee []entry, idx map[string]entry) []entry {
+	return slices.DeleteFunc(slices.Clone(ee), func(e entry) bool {
+		_, ok := idx[e.key]
+		return !ok
+	})
+}
+
+// Keys returns the key of every bookmark in the content of label file name.
+func Keys(name string, content []byte) ([]string, error) {
+	ee, err := entries(name, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys(ee), nil
+}
+
+func keys(ee []entry) []string {
+	res := make([]string, len(ee))
+	for i, e := range ee {
+		res[i] = e.key
+	}
+
+	return res
+}
+
+// integrate brings the fetched branch of remote into the current branch.
+// If the local branch has no commits of its own it is fast-forwarded,
+// otherwise both are merged bookmark by bookmark into a merge commit.
+// Uncommitted changes are replayed on top of the result afterwards.
+//
+// Everything is merged in memory first so a conflict that cannot
+// be resolved leaves the repository untouched.
+//
+// Returns the number of commits brought in from the remote.
+func (storage *gitStorage) integrate(repo *git.Repository, remote string) (pulled int, err error) {
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return 0, err
+	}
+
+	branch := head.Target()
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, branch.Short()), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// Nothing was pushed to the remote yet.
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	theirs, err := repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return 0, err
+	}
+
+	var ours *object.Commit
+	localRef, err := repo.Reference(branch, true)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+	case err != nil:
+		return 0, err
+	default:
+		ours, err = repo.CommitObject(localRef.Hash())
+		if err != nil {
+			return 0, err
+		}
+
+		if ours.Hash == theirs.Hash {
+			return 0, nil
+		}
+
+		ahead, err := theirs.IsAncestor(ours)
+		if err != nil || ahead {
+			return 0, err
+		}
+	}
+
+	var exclude []plumbing.Hash
+	if ours != nil {
+		exclude = append(exclude, ours.Hash)
+	}
+
+	pulled, err = count(repo, theirs.Hash, exclude...)
+	if err != nil {
+		return 0, err
+	}
+
+	oursFiles, err := commitFiles(ours)
+	if err != nil {
+		return 0, err
+	}
+
+	theirsFiles, err := commitFiles(theirs)
+	if err != nil {
+		return 0, err
+	}
+
+	forward := ours == nil
+	if !forward {
+		forward, err = ours.IsAncestor(theirs)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	merged := theirsFiles
+	if !forward {
+		var baseFiles map[string][]byte
+		bases, err := ours.MergeBase(theirs)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(bases) > 0 {
+			baseFiles, err = commitFiles(bases[0])
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		merged, err = merge3(baseFiles, oursFiles, theirsFiles, storage.resolve)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	local, err := worktreeFiles(storage.path)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := merge3(oursFiles, local, merged, storage.resolve)
+	if err != nil {
+		return 0, err
+	}
+
+	var author *object.Signature
+	if !forward {
+		author, err = storage.signature(repo)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			// Best effort to not lose uncommitted changes.
+			current, _ := worktreeFiles(storage.path)
+			_ = writeFiles(storage.path, current, local)
+		}
+	}()
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeFiles(storage.path, local, merged)
+	if err != nil {
+		return 0, err
+	}
+
+	if forward {
+		if ours == nil {
+			err = repo.Storer.SetReference(plumbing.NewHashReference(branch, theirs.Hash))
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = tree.Reset(&git.ResetOptions{Commit: theirs.Hash, Mode: git.MixedReset})
+	} else {
+		err = tree.AddWithOptions(&git.AddOptions{All: true})
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tree.Commit(fmt.Sprintf("Merge %s/%s", remote, branch.Short()), &git.CommitOptions{
+			Author:  author,
+			Parents: []plumbing.Hash{ours.Hash, theirs.Hash},
+		})
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return pulled, writeFiles(storage.path, merged, result)
+}
+
+// count returns the number of commits reachable from
+// from that are not reachable from any of exclude.
+func count(repo *git.Repository, from plumbing.Hash, exclude ...plumbing.Hash) (int, error) {
+	seen := map[plumbing.Hash]bool{}
+	for _, h := range exclude {
+		iter, err := repo.Log(&git.LogOptions{From: h})
+		if err != nil {
+			return 0, err
+		}
+
+		err = iter.ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return 0, err
+	}
+
+	var res int
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !seen[c.Hash] {
+			res++
+		}
+
+		return nil
+	})
+
+	return res, err
+}
+
+// commitFiles returns the content of the files at the root of the tree of c.
+func commitFiles(c *object.Commit) (map[string][]byte, error) {
+	res := map[string][]byte{}
+	if c == nil {
+		return res, nil
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range tree.Entries {
+		if !e.Mode.IsFile() {
+			continue
+		}
+
+		f, err := tree.TreeEntryFile(&e)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := f.Contents()
+		if err != nil {
+			return nil, err
+		}
+
+		res[e.Name] = []byte(content)
+	}
+
+	return res, nil
+}
+
+// worktreeFiles returns the content of the files at the root of dir.
+func worktreeFiles(dir string) (map[string][]byte, error) {
+	dd, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string][]byte{}
+	for _, d := range dd {
+		if !d.Type().IsRegular() {
+			continue
+		}
+
+		res[d.Name()], err = os.ReadFile(filepath.Join(dir, d.Name()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// writeFiles changes the files at the root of dir from old to files.
+func writeFiles(dir string, old, files map[string][]byte) error {
+	for name, content := range files {
+		if prev, ok := old[name]; ok && bytes.Equal(prev, content) {
+			continue
+		}
+
+		err := os.WriteFile(filepath.Join(dir, name), content, config.StdFileMode)
+		if err != nil {
+			return err
+		}
+	}
+
+	for name := range old {
+		if _, ok := files[name]; ok {
+			continue
+		}
+
+		err := os.Remove(filepath.Join(dir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
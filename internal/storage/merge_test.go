@@ -0,0 +1,380 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
+	"github.com/loghinalexandru/anchor/internal/model"
+)
+
+const (
+	idA = "0192f0a0-0000-7000-8000-00000000000a"
+	idB = "0192f0a0-0000-7000-8000-00000000000b"
+	idC = "0192f0a0-0000-7000-8000-00000000000c"
+	idD = "0192f0a0-0000-7000-8000-00000000000d"
+	idE = "0192f0a0-0000-7000-8000-00000000000e"
+)
+
+func line(title string, id string) string {
+	return fmt.Sprintf("%q %q %q %q\n", title, "https://example.com/"+strings.ToLower(title), "", id)
+}
+
+func lines(ll ...string) []byte {
+	return []byte(strings.Join(ll, ""))
+}
+
+func TestMergeLabel(t *testing.T) {
+	t.Parallel()
+
+	theirsWins := func(c Conflict) (*model.Bookmark, error) {
+		return c.Theirs, nil
+	}
+
+	tcs := []struct {
+		name    string
+		base    []byte
+		ours    []byte
+		theirs  []byte
+		resolve Resolver
+		want    []byte
+		err     error
+	}{
+		{
+			name:   "additions on both sides",
+			base:   lines(line("A", idA)),
+			ours:   lines(line("A", idA), line("B", idB)),
+			theirs: lines(line("C", idC), line("A", idA)),
+			want:   lines(line("C", idC), line("A", idA), line("B", idB)),
+		},
+		{
+			name:   "deletions are propagated",
+			base:   lines(line("A", idA), line("B", idB), line("C", idC)),
+			ours:   lines(line("A", idA), line("C", idC)),
+			theirs: lines(line("A", idA), line("B", idB), line("C", idC), line("D", idD)),
+			want:   lines(line("A", idA), line("C", idC), line("D", idD)),
+		},
+		{
+			name:   "edits are propagated",
+			base:   lines(line("A", idA), line("B", idB)),
+			ours:   lines(line("A2", idA), line("B", idB)),
+			theirs: lines(line("A", idA), line("B2", idB)),
+			want:   lines(line("A2", idA), line("B2", idB)),
+		},
+		{
+			name:   "deletion wins over edit",
+			base:   lines(line("A", idA), line("B", idB)),
+			ours:   lines(line("B", idB)),
+			theirs: lines(line("A2", idA), line("B", idB)),
+			want:   lines(line("B", idB)),
+		},
+		{
+			name:   "reorder on theirs is kept",
+			base:   lines(line("A", idA), line("B", idB), line("C", idC)),
+			ours:   lines(line("A", idA), line("B", idB), line("C", idC), line("D", idD)),
+			theirs: lines(line("C", idC), line("B", idB), line("A", idA)),
+			want:   lines(line("C", idC), line("D", idD), line("B", idB), line("A", idA)),
+		},
+		{
+			name:   "same edit on both sides",
+			base:   lines(line("A", idA)),
+			ours:   lines(line("A2", idA)),
+			theirs: lines(line("A2", idA)),
+			want:   lines(line("A2", idA)),
+		},
+		{
+			name:   "lines without id are matched by URL",
+			base:   []byte(`"A" "https://example.com/a" ""` + "\n"),
+			ours:   []byte(`"A" "https://example.com/a" ""` + "\n" + line("B", idB)),
+			theirs: []byte(`"A2" "https://example.com/a" ""` + "\n"),
+			want:   []byte(`"A2" "https://example.com/a" ""` + "\n" + line("B", idB)),
+		},
+		{
+			name:   "conflict without resolver",
+			base:   lines(line("A", idA)),
+			ours:   lines(line("A2", idA)),
+			theirs: lines(line("A3", idA)),
+			err:    ErrMergeConflict,
+		},
+		{
+			name:    "conflict with resolver",
+			base:    lines(line("A", idA), line("B", idB)),
+			ours:    lines(line("A2", idA), line("B", idB)),
+			theirs:  lines(line("A3", idA)),
+			resolve: theirsWins,
+			want:    lines(line("A3", idA)),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := mergeLabel("label", tc.base, tc.ours, tc.theirs, tc.resolve)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error; want %q, got %q", tc.err, err)
+			}
+
+			if diff := cmp.Diff(string(got), string(tc.want)); diff != "" {
+				t.Errorf("output not matching; (-got, +want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	t.Parallel()
+
+	base := map[string][]byte{
+		"kept":    lines(line("A", idA)),
+		"removed": lines(line("A", idA)),
+		"emptied": lines(line("A", idA)),
+	}
+	ours := map[string][]byte{
+		"kept":    lines(line("A", idA)),
+		"emptied": lines(line("A", idA), line("B", idB)),
+		"new":     lines(line("C", idC)),
+	}
+	theirs := map[string][]byte{
+		"kept":    lines(line("A", idA), line("D", idD)),
+		"removed": lines(line("A", idA)),
+	}
+
+	got, err := merge3(base, ours, theirs, nil)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	want := map[string]string{
+		"kept":    string(lines(line("A", idA), line("D", idD))),
+		"emptied": string(lines(line("B", idB))),
+		"new":     string(lines(line("C", idC))),
+	}
+
+	gotStr := map[string]string{}
+	for k, v := range got {
+		gotStr[k] = string(v)
+	}
+
+	if diff := cmp.Diff(gotStr, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA)))
+	push(t, origin)
+
+	laptop := filepath.Join(dir, "laptop")
+	repo := clone(t, remote, laptop)
+
+	// Diverge: the remote gets B while the laptop commits C
+	// and has D added to another label but not yet synced.
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA), line("B", idB)))
+	push(t, origin)
+	commit(t, repo, laptop, "root", lines(line("A", idA), line("C", idC)))
+	write(t, laptop, "other", lines(line("D", idD)))
+
+	storage := newGitStorage(laptop)
+	err = storage.Update()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(laptop, "root"))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if diff := cmp.Diff(string(got), string(lines(line("A", idA), line("B", idB), line("C", idC)))); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	merge, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if merge.NumParents() != 2 {
+		t.Errorf("unexpected number of merge parents; want 2, got %d", merge.NumParents())
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	status, err := tree.Status()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if len(status) != 1 || status.File("other").Worktree != git.Untracked {
+		t.Errorf("unexpected pending changes; want only the unsynced label, got %s", status)
+	}
+}
+
+// seed creates a repository at path pushing to the empty remote.
+func TestUpdateFastForward(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA), line("B", idB)))
+	push(t, origin)
+
+	laptop := filepath.Join(dir, "laptop")
+	repo := clone(t, remote, laptop)
+	before, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	// The remote renames B while the laptop has an unsynced rename of A.
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA), line("B2", idB)))
+	push(t, origin)
+	write(t, laptop, "root", lines(line("A2", idA), line("B", idB)))
+
+	err = newGitStorage(laptop).Update()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(laptop, "root"))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if diff := cmp.Diff(string(got), string(lines(line("A2", idA), line("B2", idB)))); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	remoteHead, err := origin.Head()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if head.Hash() == before.Hash() || head.Hash() != remoteHead.Hash() {
+		t.Errorf("unexpected head after fast-forward; want %s, got %s", remoteHead.Hash(), head.Hash())
+	}
+}
+
+func seed(t *testing.T, remote string, path string) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{remote}})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	return identify(t, repo)
+}
+
+func clone(t *testing.T, remote string, path string) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainClone(path, false, &git.CloneOptions{URL: remote})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	return identify(t, repo)
+}
+
+func identify(t *testing.T, repo *git.Repository) *git.Repository {
+	t.Helper()
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	cfg.User.Name = "anchor"
+	cfg.User.Email = "anchor@example.com"
+	err = repo.SetConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	return repo
+}
+
+func write(t *testing.T, dir string, name string, content []byte) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(dir, name), content, 0o666)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+}
+
+func commit(t *testing.T, repo *git.Repository, dir string, name string, content []byte) {
+	t.Helper()
+
+	write(t, dir, name, content)
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	_, err = tree.Add(name)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	_, err = tree.Commit("change "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "anchor", Email: "anchor@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+}
+
+func push(t *testing.T, repo *git.Repository) {
+	t.Helper()
+
+	err := repo.Push(&git.PushOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidSyncMode = errors.New("invalid sync mode")
+)
+
+// SyncMode selects when anchor talks to the remote on its own.
+type SyncMode int
+
+const (
+	// AlwaysSync pulls before every command, changes are pushed with anchor sync.
+	AlwaysSync SyncMode = iota
+	// AutoSync pulls before every command and commits and pushes
+	// right after every command changing bookmarks.
+	AutoSync
+	// PullOnlySync pulls before every command and never pushes,
+	// not even on anchor sync, e.g. for read-only machines.
+	PullOnlySync
+	// ManualSync only talks to the remote on anchor sync.
+	ManualSync
+)
+
+var syncModeNames = []string{"always", "auto", "pull-only", "manual"}
+
+func ParseSyncMode(s string) (SyncMode, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+
+	// Before sync modes were validated any value other than
+	// "always" turned pulling off, "none" being the documented one.
+	if name == "none" {
+		return ManualSync, nil
+	}
+
+	idx := slices.Index(syncModeNames, name)
+	if idx == -1 {
+		return AlwaysSync, fmt.Errorf("%q: %w", s, ErrInvalidSyncMode)
+	}
+
+	return SyncMode(idx), nil
+}
+
+func (m SyncMode) String() string {
+	return syncModeNames[m]
+}
+
+// Pulls reports whether the latest changes are pulled before every command.
+func (m SyncMode) Pulls() bool {
+	return m != ManualSync
+}
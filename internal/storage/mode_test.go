@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSyncMode(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		in   string
+		want SyncMode
+		err  error
+	}{
+		{in: "always", want: AlwaysSync},
+		{in: " Auto ", want: AutoSync},
+		{in: "pull-only", want: PullOnlySync},
+		{in: "manual", want: ManualSync},
+		{in: "none", want: ManualSync},
+		{in: "push-only", want: AlwaysSync, err: ErrInvalidSyncMode},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseSyncMode(tc.in)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error; want %q, got %q", tc.err, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
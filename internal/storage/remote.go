@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+var (
+	ErrRemoteExists   = errors.New("remote already exists, change its URL instead")
+	ErrRemoteNotFound = errors.New("remote not found, add it first")
+)
+
+// AddRemote attaches the remote at rawURL under the configured remote name
+// and brings in its changes. Local commits are pushed on the next sync.
+func (storage *gitStorage) AddRemote(rawURL string) error {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return err
+	}
+
+	_, err = storage.authFor(rawURL)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: storage.remote,
+		URLs: []string{rawURL},
+	})
+
+	if errors.Is(err, git.ErrRemoteExists) {
+		return fmt.Errorf("%q: %w", storage.remote, ErrRemoteExists)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if storage.branch == "" {
+		err = storage.track(repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return storage.Update()
+}
+
+// track renames the current branch after the default branch of the remote
+// so a repository created locally, on go-git's default "master", merges
+// and pushes to e.g. "main" instead of creating a branch next to it.
+func (storage *gitStorage) track(repo *git.Repository) error {
+	branch, err := storage.remoteHead(repo)
+	if err != nil || branch == "" {
+		return err
+	}
+
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil || head.Target() == branch {
+		return err
+	}
+
+	_, err = repo.Reference(branch, false)
+	if err == nil {
+		// Never move a local branch that already exists.
+		return nil
+	}
+
+	if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	current, err := repo.Reference(head.Target(), false)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		// Nothing committed yet, only HEAD has to point to the new branch.
+	case err != nil:
+		return err
+	default:
+		err = repo.Storer.SetReference(plumbing.NewHashReference(branch, current.Hash()))
+		if err != nil {
+			return err
+		}
+
+		err = repo.Storer.RemoveReference(head.Target())
+		if err != nil {
+			return err
+		}
+	}
+
+	return repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch))
+}
+
+// remoteHead returns the default branch of the remote
+// or an empty name if nothing was pushed to it yet.
+func (storage *gitStorage) remoteHead(repo *git.Repository) (plumbing.ReferenceName, error) {
+	rmt, err := repo.Remote(storage.remote)
+	if err != nil {
+		return "", err
+	}
+
+	auth, err := storage.remoteAuth(repo, storage.remote)
+	if err != nil {
+		return "", err
+	}
+
+	refs, err := rmt.List(&git.ListOptions{Auth: auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	idx := slices.IndexFunc(refs, func(ref *plumbing.Reference) bool {
+		return ref.Name() == plumbing.HEAD
+	})
+
+	if idx == -1 {
+		return "", nil
+	}
+
+	head := refs[idx]
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target(), nil
+	}
+
+	// Servers not advertising where HEAD points to.
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			return ref.Name(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// SetRemoteURL points the configured remote to rawURL.
+func (storage *gitStorage) SetRemoteURL(rawURL string) error {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return err
+	}
+
+	_, err = storage.authFor(rawURL)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+
+	rmt, ok := cfg.Remotes[storage.remote]
+	if !ok {
+		return fmt.Errorf("%q: %w", storage.remote, ErrRemoteNotFound)
+	}
+
+	rmt.URLs = []string{rawURL}
+	return repo.SetConfig(cfg)
+}
+
+// RemoteURL returns the URL of the configured remote
+// or an empty string if the repository has none yet.
+func (storage *gitStorage) RemoteURL() (string, error) {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return "", err
+	}
+
+	rmt, err := repo.Remote(storage.remote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", nil
+	}
+
+	if err != nil || len(rmt.Config().URLs) == 0 {
+		return "", err
+	}
+
+	return rmt.Config().URLs[0], nil
+}
+
+// hasRemote reports whether the configured remote exists. Repositories
+// initialized without one only keep their history locally.
+func (storage *gitStorage) hasRemote(repo *git.Repository) (bool, error) {
+	_, err := repo.Remote(storage.remote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
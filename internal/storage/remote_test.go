@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInitWithoutRemote(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write(t, dir, "root", lines(line("A", idA)))
+
+	storage := newGitStorage(dir, WithAuthorName("anchor"), WithAuthorEmail("anchor@example.com"))
+	err := storage.Init()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	revisions, err := storage.Log(10, nil)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if len(revisions) != 1 || len(revisions[0].Changes) != 1 {
+		t.Fatalf("unexpected revisions; want existing bookmarks committed, got %+v", revisions)
+	}
+
+	// Running init again keeps the repository as it is.
+	err = storage.Init()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	write(t, dir, "root", lines(line("A", idA), line("B", idB)))
+	summary, err := storage.Sync("add B")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if summary != (Summary{Committed: true}) {
+		t.Errorf("unexpected summary; want only a local commit, got %+v", summary)
+	}
+
+	pending, err := storage.Unpushed()
+	if err != nil || pending != 0 {
+		t.Errorf("unexpected commits to push without a remote; got %d, %q", pending, err)
+	}
+}
+
+func TestInitExistingBookmarks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA)))
+	push(t, origin)
+
+	// Bookmarks kept on local storage before switching to git.
+	laptop := t.TempDir()
+	write(t, laptop, "go", lines(line("B", idB)))
+
+	storage := newGitStorage(laptop, WithAuthorName("anchor"), WithAuthorEmail("anchor@example.com"))
+	err = storage.Init(remote)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	assertContent(t, laptop, "root", lines(line("A", idA)))
+	assertContent(t, laptop, "go", lines(line("B", idB)))
+
+	summary, err := storage.Sync("sync")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if summary.Pushed == 0 {
+		t.Errorf("unexpected summary; want local history pushed, got %+v", summary)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	laptop := filepath.Join(dir, "laptop")
+	storage := newGitStorage(laptop, WithAuthorName("anchor"), WithAuthorEmail("anchor@example.com"))
+	err = storage.Init()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	err = storage.SetRemoteURL(remote)
+	if !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("unexpected error; want %q, got %q", ErrRemoteNotFound, err)
+	}
+
+	write(t, laptop, "root", lines(line("A", idA)))
+	err = storage.AddRemote(remote)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	err = storage.AddRemote(remote)
+	if !errors.Is(err, ErrRemoteExists) {
+		t.Errorf("unexpected error; want %q, got %q", ErrRemoteExists, err)
+	}
+
+	summary, err := storage.Sync("add A")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if summary != (Summary{Committed: true, Pushed: 1}) {
+		t.Errorf("unexpected summary; got %+v", summary)
+	}
+
+	moved := filepath.Join(dir, "moved.git")
+	_, err = git.PlainClone(moved, true, &git.CloneOptions{URL: remote})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	err = storage.SetRemoteURL(moved)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got, err := storage.RemoteURL()
+	if err != nil || got != moved {
+		t.Errorf("unexpected remote URL; want %q, got %q, %q", moved, got, err)
+	}
+}
+
+func TestInitMainBranch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	bare, err := git.PlainInitWithOptions(remote, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+		Bare:        true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	err = origin.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main")))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA)))
+	push(t, origin)
+
+	laptop := t.TempDir()
+	write(t, laptop, "root", lines(line("B", idB)))
+
+	storage := newGitStorage(laptop, WithAuthorName("anchor"), WithAuthorEmail("anchor@example.com"))
+	err = storage.Init(remote)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(laptop, "root"))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if diff := cmp.Diff(string(got), string(lines(line("A", idA), line("B", idB)))); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+
+	_, err = storage.Sync("sync")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	_, err = bare.Reference(plumbing.NewBranchReferenceName("master"), false)
+	if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		t.Errorf("unexpected master branch on remote; got %q", err)
+	}
+
+	main, err := bare.Reference(plumbing.NewBranchReferenceName("main"), false)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	pushed, err := bare.CommitObject(main.Hash())
+	if err != nil || pushed.NumParents() != 2 {
+		t.Errorf("missing merge commit on main; got %v, %q", pushed, err)
+	}
+}
@@ -1,11 +1,18 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/loghinalexandru/anchor/internal/config"
 )
 
+var (
+	ErrInvalidKind = errors.New("invalid storage kind")
+)
+
 type Kind int
 
 const (
@@ -13,20 +20,20 @@ const (
 	Git
 )
 
+var kindNames = []string{"local", "git"}
+
 type Storer interface {
 	Init(args ...string) error
 	Store(msg string) error
 }
 
-func New(k Kind) Storer {
+// New returns the storer of kind k. Options only apply to git storage
+// and authentication is resolved lazily on the first remote operation,
+// so commands that never reach the remote work without credentials.
+func New(k Kind, opts ...Option) Storer {
 	switch k {
 	case Git:
-		storer, err := newGitStorage(config.DataDirPath())
-		if err != nil {
-			panic(err)
-		}
-
-		return storer
+		return newGitStorage(config.DataDirPath(), opts...)
 	default:
 		return newLocalStorage(config.DataDirPath())
 	}
@@ -40,3 +47,17 @@ func Parse(s string) Kind {
 		return Local
 	}
 }
+
+// ParseKind is the strict version of Parse rejecting unknown kinds.
+func ParseKind(s string) (Kind, error) {
+	idx := slices.Index(kindNames, strings.ToLower(strings.TrimSpace(s)))
+	if idx == -1 {
+		return Local, fmt.Errorf("%q: %w", s, ErrInvalidKind)
+	}
+
+	return Kind(idx), nil
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseKind(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		in   string
+		want Kind
+		err  error
+	}{
+		{in: "local", want: Local},
+		{in: " Git ", want: Git},
+		{in: "s3", want: Local, err: ErrInvalidKind},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseKind(tc.in)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error; want %q, got %q", tc.err, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
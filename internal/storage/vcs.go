@@ -1,130 +1,637 @@
 package storage
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
+	"maps"
+	"os"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
 const (
-	stdUser = "git"
+	stdUser         = "git"
+	stdInitMsg      = "Initialize bookmarks"
+	maxPushAttempts = 3
 )
 
 var (
-	ErrInvalidURL = errors.New("not a valid URL")
+	ErrMissingRemote = errors.New("remote has no URL")
+	ErrMissingAuthor = errors.New("missing commit author, set git.author.name and git.author.email")
 )
 
+// Summary describes what a sync exchanged with the remote.
+type Summary struct {
+	// Committed is set if there were local changes to commit.
+	Committed bool
+	// Pulled and Pushed count the commits received and sent.
+	Pulled int
+	Pushed int
+}
+
+// Option configures the git storage.
+type Option func(*gitStorage)
+
 type gitStorage struct {
-	path string
-	auth transport.AuthMethod
+	path     string
+	mode     AuthMode
+	key      string
+	user     string
+	tokenEnv string
+	prompt   func(string) (string, error)
+	resolve  Resolver
+	auths    map[string]transport.AuthMethod
+	remote   string
+	branch   string
+	name     string
+	email    string
 }
 
-func newGitStorage(path string) (*gitStorage, error) {
-	auth, err := ssh.NewSSHAgentAuth(stdUser)
-	if err != nil {
-		return nil, err
+func newGitStorage(path string, opts ...Option) *gitStorage {
+	res := &gitStorage{
+		path:     path,
+		user:     stdUser,
+		tokenEnv: stdTokenEnv,
+		auths:    map[string]transport.AuthMethod{},
+		remote:   git.DefaultRemoteName,
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// WithAuth sets how to authenticate against the remote,
+// by default it is inferred from the remote URL.
+func WithAuth(mode AuthMode) Option {
+	return func(g *gitStorage) {
+		g.mode = mode
+	}
+}
+
+// WithKey sets the SSH private key file used by KeyAuth.
+func WithKey(path string) Option {
+	return func(g *gitStorage) {
+		g.key = path
+	}
+}
+
+// WithUser sets the SSH user or HTTPS username if the remote URL has none.
+func WithUser(user string) Option {
+	return func(g *gitStorage) {
+		if user != "" {
+			g.user = user
+		}
+	}
+}
+
+// WithTokenEnv sets the environment variable TokenAuth reads the token from.
+func WithTokenEnv(name string) Option {
+	return func(g *gitStorage) {
+		if name != "" {
+			g.tokenEnv = name
+		}
+	}
+}
+
+// WithPrompt sets how to ask for the passphrase of an encrypted private key.
+func WithPrompt(prompt func(string) (string, error)) Option {
+	return func(g *gitStorage) {
+		g.prompt = prompt
+	}
+}
+
+// WithResolver sets how to pick between the local and remote version
+// of a bookmark edited differently on both sides when pulling.
+func WithResolver(resolve Resolver) Option {
+	return func(g *gitStorage) {
+		g.resolve = resolve
+	}
+}
+
+// WithRemote sets the name of the remote to sync with (default: origin).
+func WithRemote(name string) Option {
+	return func(g *gitStorage) {
+		if name != "" {
+			g.remote = name
+		}
+	}
+}
+
+// WithBranch sets the branch bookmarks are committed to, by default
+// the branch checked out or the default branch of the remote on init.
+func WithBranch(name string) Option {
+	return func(g *gitStorage) {
+		g.branch = name
 	}
+}
+
+// WithAuthorName and WithAuthorEmail set the identity commits are made with.
+// Unset fields fall back to the user.name and user.email of the git config.
+func WithAuthorName(name string) Option {
+	return func(g *gitStorage) {
+		g.name = name
+	}
+}
 
-	return &gitStorage{
-		path: path,
-		auth: auth,
-	}, nil
+func WithAuthorEmail(email string) Option {
+	return func(g *gitStorage) {
+		g.email = email
+	}
 }
 
+// Init creates the repository holding the bookmarks. Without a URL it is
+// only created locally and a remote can be added later. With a URL the
+// remote is cloned, unless it is empty or there are bookmarks already in
+// which case the repository is created locally, the bookmarks committed
+// and the changes of the remote merged in.
 func (storage *gitStorage) Init(args ...string) error {
 	if len(args) == 0 {
-		return ErrInvalidURL
+		_, err := storage.initLocal()
+		return err
 	}
 
-	_, err := git.PlainClone(storage.path, false, &git.CloneOptions{
-		URL:  args[0],
-		Auth: storage.auth,
-	})
+	rawURL := args[0]
+	repo, err := git.PlainOpen(storage.path)
+	if err == nil {
+		return storage.reinit(repo, rawURL)
+	}
 
-	if err == git.ErrRepositoryAlreadyExists {
-		repo, _ := git.PlainOpen(storage.path)
-		rmts, _ := repo.Remotes()
+	if !errors.Is(err, git.ErrRepositoryNotExists) {
+		return err
+	}
 
-		for _, rmt := range rmts {
-			if slices.Contains(rmt.Config().URLs, args[0]) {
-				return nil
-			}
+	empty, err := isEmptyDir(storage.path)
+	if err != nil {
+		return err
+	}
+
+	if empty {
+		err = storage.clone(rawURL)
+		if !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return err
 		}
 	}
 
+	_, err = storage.initLocal()
+	if err != nil {
+		return err
+	}
+
+	return storage.AddRemote(rawURL)
+}
+
+// reinit handles init on an existing repository. It is a no-op if the
+// remote is already there and adds it to repositories created without one.
+func (storage *gitStorage) reinit(repo *git.Repository, rawURL string) error {
+	ok, err := storage.hasRemote(repo)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return storage.AddRemote(rawURL)
+	}
+
+	current, err := storage.RemoteURL()
+	if err != nil || current == rawURL {
+		return err
+	}
+
+	return git.ErrRepositoryAlreadyExists
+}
+
+func (storage *gitStorage) clone(rawURL string) error {
+	auth, err := storage.authFor(rawURL)
+	if err != nil {
+		return err
+	}
+
+	var ref plumbing.ReferenceName
+	if storage.branch != "" {
+		ref = plumbing.NewBranchReferenceName(storage.branch)
+	}
+
+	_, err = git.PlainClone(storage.path, false, &git.CloneOptions{
+		URL:           rawURL,
+		Auth:          auth,
+		RemoteName:    storage.remote,
+		ReferenceName: ref,
+	})
+
 	return err
 }
 
+// initLocal creates the repository in the data directory and commits the
+// bookmarks already there, e.g. when switching from local storage.
+func (storage *gitStorage) initLocal() (*git.Repository, error) {
+	opts := &git.PlainInitOptions{}
+	if storage.branch != "" {
+		opts.InitOptions.DefaultBranch = plumbing.NewBranchReferenceName(storage.branch)
+	}
+
+	repo, err := git.PlainInitWithOptions(storage.path, opts)
+	if errors.Is(err, git.ErrRepositoryAlreadyExists) {
+		return git.PlainOpen(storage.path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tree.AddWithOptions(&git.AddOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := tree.Status()
+	if err != nil || status.IsClean() {
+		return repo, err
+	}
+
+	author, err := storage.signature(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tree.Commit(stdInitMsg, &git.CommitOptions{Author: author})
+	return repo, err
+}
+
+// Update fetches the remote and integrates its changes with the local
+// ones, see integrate. Uncommitted changes are kept as they are.
 func (storage *gitStorage) Update() error {
+	err := storage.Fetch()
+	if err != nil {
+		return err
+	}
+
+	return storage.Merge()
+}
+
+// Fetch downloads the changes of the remote without integrating them.
+func (storage *gitStorage) Fetch() error {
 	repo, err := git.PlainOpen(storage.path)
 	if err != nil {
 		return err
 	}
 
-	tree, err := repo.Worktree()
+	ok, err := storage.hasRemote(repo)
+	if err != nil || !ok {
+		return err
+	}
+
+	auth, err := storage.remoteAuth(repo, storage.remote)
 	if err != nil {
 		return err
 	}
 
-	err = tree.Pull(&git.PullOptions{
-		RemoteName: git.DefaultRemoteName,
-		Auth:       storage.auth,
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: storage.remote,
+		Auth:       auth,
 	})
 
-	if errors.Is(err, git.NoErrAlreadyUpToDate) {
-		return nil
+	if err != nil && !upToDate(err) {
+		return err
 	}
 
+	return nil
+}
+
+// Merge integrates the changes fetched last time without
+// going over the network, see integrate.
+func (storage *gitStorage) Merge() error {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return err
+	}
+
+	err = storage.checkout(repo)
+	if err != nil {
+		return err
+	}
+
+	_, err = storage.integrate(repo, storage.remote)
 	return err
 }
 
 func (storage *gitStorage) Store(msg string) error {
+	_, err := storage.Sync(msg)
+	return err
+}
+
+// Sync commits the local changes with msg, integrates the changes of the
+// remote and pushes the result. If the push is rejected because the remote
+// moved in the meantime, it fetches and integrates again before retrying.
+func (storage *gitStorage) Sync(msg string) (Summary, error) {
+	var res Summary
 	repo, err := git.PlainOpen(storage.path)
 	if err != nil {
-		return err
+		return res, err
+	}
+
+	err = storage.checkout(repo)
+	if err != nil {
+		return res, err
 	}
 
 	tree, err := repo.Worktree()
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	_, err = tree.Add(".")
+	err = tree.AddWithOptions(&git.AddOptions{All: true})
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	_, err = tree.Commit(msg, &git.CommitOptions{})
+	status, err := tree.Status()
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	err = repo.Push(&git.PushOptions{
-		RemoteName: git.DefaultRemoteName,
-		Auth:       storage.auth,
-	})
+	if !status.IsClean() {
+		author, err := storage.signature(repo)
+		if err != nil {
+			return res, err
+		}
 
-	return err
+		_, err = tree.Commit(msg, &git.CommitOptions{Author: author})
+		if err != nil {
+			return res, err
+		}
+
+		res.Committed = true
+	}
+
+	ok, err := storage.hasRemote(repo)
+	if err != nil || !ok {
+		return res, err
+	}
+
+	auth, err := storage.remoteAuth(repo, storage.remote)
+	if err != nil {
+		return res, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = repo.Fetch(&git.FetchOptions{
+			RemoteName: storage.remote,
+			Auth:       auth,
+		})
+		if err != nil && !upToDate(err) {
+			return res, err
+		}
+
+		pulled, err := storage.integrate(repo, storage.remote)
+		res.Pulled += pulled
+		if err != nil {
+			return res, err
+		}
+
+		pending, err := unpushed(repo, storage.remote)
+		if err != nil || pending == 0 {
+			return res, err
+		}
+
+		head, err := repo.Head()
+		if err != nil {
+			return res, err
+		}
+
+		err = repo.Push(&git.PushOptions{
+			RemoteName: storage.remote,
+			Auth:       auth,
+			RefSpecs:   []config.RefSpec{config.RefSpec(head.Name() + ":" + head.Name())},
+		})
+
+		if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+			res.Pushed = pending
+			return res, nil
+		}
+
+		if !rejected(err) || attempt == maxPushAttempts {
+			return res, err
+		}
+	}
 }
 
-func (storage *gitStorage) Diff() (string, error) {
+// Unpushed returns the number of local commits missing from the remote.
+func (storage *gitStorage) Unpushed() (int, error) {
 	repo, err := git.PlainOpen(storage.path)
 	if err != nil {
-		return "", err
+		return 0, err
+	}
+
+	ok, err := storage.hasRemote(repo)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return unpushed(repo, storage.remote)
+}
+
+// Diff returns the bookmarks changed in the working tree since the last
+// commit by comparing both versions of every label git reports as changed.
+func (storage *gitStorage) Diff() ([]Change, error) {
+	repo, err := git.PlainOpen(storage.path)
+	if err != nil {
+		return nil, err
 	}
 
 	tree, err := repo.Worktree()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	status, err := tree.Status()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if status.IsClean() {
+		return nil, nil
+	}
+
+	var head *object.Commit
+	ref, err := repo.Head()
+	switch {
+	case err == nil:
+		head, err = repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil, err
+	}
+
+	before, err := commitFiles(head)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := worktreeFiles(storage.path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffFiles(before, after, func(name string) bool {
+		_, changed := status[name]
+		return changed
+	})
+	if err != nil || len(changes) > 0 {
+		return changes, err
+	}
+
+	// The worktree is dirty but no bookmark changed, report the files
+	// so they are still synced instead of lingering uncommitted.
+	for _, name := range slices.Sorted(maps.Keys(status)) {
+		changes = append(changes, Change{Label: name, Kind: Touched})
+	}
+
+	return changes, nil
+}
+
+// checkout switches to the configured branch keeping uncommitted changes.
+// A missing branch starts from its counterpart on the remote, if fetched,
+// or from the current commit otherwise.
+func (storage *gitStorage) checkout(repo *git.Repository) error {
+	if storage.branch == "" {
+		return nil
+	}
+
+	branch := plumbing.NewBranchReferenceName(storage.branch)
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil || head.Target() == branch {
+		return err
+	}
+
+	opts := &git.CheckoutOptions{Branch: branch, Keep: true}
+	_, err = repo.Reference(branch, true)
+	switch {
+	case err == nil:
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return err
+	default:
+		start, err := repo.Reference(plumbing.NewRemoteReferenceName(storage.remote, storage.branch), true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			start, err = repo.Head()
+		}
+
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			// Nothing committed yet, the branch is created on the first commit.
+			return repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		opts.Create, opts.Hash = true, start.Hash()
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return tree.Checkout(opts)
+}
+
+// signature returns the configured commit identity, falling back
+// to the local and global git config for the fields left unset.
+func (storage *gitStorage) signature(repo *git.Repository) (*object.Signature, error) {
+	name, email := storage.name, storage.email
+	if name == "" || email == "" {
+		cfg, err := repo.ConfigScoped(config.GlobalScope)
+		if err != nil {
+			return nil, err
+		}
+
+		name = cmp.Or(name, cfg.Author.Name, cfg.User.Name)
+		email = cmp.Or(email, cfg.Author.Email, cfg.User.Email)
+	}
+
+	if name == "" || email == "" {
+		return nil, ErrMissingAuthor
+	}
+
+	return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+// unpushed returns the number of commits of the current
+// branch that are missing from its counterpart on remote.
+func unpushed(repo *git.Repository, remote string) (int, error) {
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	var exclude []plumbing.Hash
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, head.Name().Short()), true)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+	case err != nil:
+		return 0, err
+	default:
+		exclude = append(exclude, remoteRef.Hash())
+	}
+
+	return count(repo, head.Hash(), exclude...)
+}
+
+func isEmptyDir(path string) (bool, error) {
+	dd, err := os.ReadDir(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+
+	return len(dd) == 0, err
+}
+
+// upToDate reports whether a fetch failed only because there was nothing
+// to fetch, including from a remote that nothing was pushed to yet.
+func upToDate(err error) bool {
+	return errors.Is(err, git.NoErrAlreadyUpToDate) || errors.Is(err, transport.ErrEmptyRemoteRepository)
+}
+
+// rejected reports whether err is a push refused because the remote has commits
+// missing locally. go-git reports these only as plain errors so match the text.
+func rejected(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "non-fast-forward") || strings.Contains(msg, "fetch first")
+}
+
+// remoteAuth returns the auth method for the first URL of the remote called name.
+func (storage *gitStorage) remoteAuth(repo *git.Repository, name string) (transport.AuthMethod, error) {
+	rmt, err := repo.Remote(name)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := rmt.Config().URLs
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%q: %w", name, ErrMissingRemote)
 	}
 
-	return status.String(), nil
+	return storage.authFor(urls[0])
 }
@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/file"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA)))
+	push(t, origin)
+
+	laptop := filepath.Join(dir, "laptop")
+	repo := clone(t, remote, laptop)
+
+	// A commit left unpushed by a failed sync and an unsynced change
+	// while the remote moved on, which used to reject the push.
+	commit(t, repo, laptop, "root", lines(line("A", idA), line("B", idB)))
+	write(t, laptop, "other", lines(line("C", idC)))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("D", idD), line("A", idA)))
+	push(t, origin)
+
+	storage := newGitStorage(laptop)
+	got, err := storage.Sync("sync")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	// The unpushed commit, the one of the unsynced change and the merge.
+	want := Summary{Committed: true, Pulled: 1, Pushed: 3}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+
+	pending, err := storage.Unpushed()
+	if err != nil || pending != 0 {
+		t.Errorf("unexpected commits left to push; got %d, %q", pending, err)
+	}
+
+	got, err = storage.Sync("sync")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if diff := cmp.Diff(got, Summary{}); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+
+	bare, err := git.PlainOpen(remote)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	remoteHead, err := bare.Head()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if remoteHead.Hash() != head.Hash() {
+		t.Errorf("unexpected remote head; want %s, got %s", head.Hash(), remoteHead.Hash())
+	}
+}
+
+func TestSyncBranch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA)))
+	push(t, origin)
+
+	laptop := filepath.Join(dir, "laptop")
+	clone(t, remote, laptop)
+	write(t, laptop, "root", lines(line("A", idA), line("B", idB)))
+
+	storage := newGitStorage(laptop,
+		WithBranch("bookmarks"),
+		WithAuthorName("Jane"),
+		WithAuthorEmail("jane@example.com"),
+	)
+
+	_, err = storage.Sync("sync")
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	bare, err := git.PlainOpen(remote)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	ref, err := bare.Reference(plumbing.NewBranchReferenceName("bookmarks"), true)
+	if err != nil {
+		t.Fatalf("missing branch on remote; got %q", err)
+	}
+
+	c, err := bare.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	if c.Author.Name != "Jane" || c.Author.Email != "jane@example.com" {
+		t.Errorf("unexpected author; got %s", c.Author)
+	}
+}
+
+// racingTransport runs race before every push so the remote
+// can advance between the fetch and the push of a sync.
+type racingTransport struct {
+	transport.Transport
+	race func()
+}
+
+func (r racingTransport) NewReceivePackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.ReceivePackSession, error) {
+	r.race()
+	return r.Transport.NewReceivePackSession(ep, auth)
+}
+
+func TestSyncRejected(t *testing.T) {
+	tcs := []struct {
+		name   string
+		races  int
+		pushes int
+		err    bool
+	}{
+		{name: "remote advances once", races: 1, pushes: 2},
+		{name: "remote keeps advancing", races: maxPushAttempts, pushes: maxPushAttempts, err: true},
+	}
+
+	for i, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			remote := filepath.Join(dir, "remote.git")
+			_, err := git.PlainInit(remote, true)
+			if err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			seedDir := filepath.Join(dir, "seed")
+			origin := seed(t, remote, seedDir)
+			commit(t, origin, seedDir, "root", lines(line("A", idA)))
+			push(t, origin)
+
+			// Protocols are global, so every case gets its own scheme.
+			scheme := fmt.Sprintf("race%d", i)
+			var pushes int
+			client.InstallProtocol(scheme, racingTransport{
+				Transport: file.DefaultClient,
+				race: func() {
+					pushes++
+					if pushes <= tc.races {
+						commit(t, origin, seedDir, "go", lines(line(fmt.Sprintf("C%d", pushes), idC)))
+						push(t, origin)
+					}
+				},
+			})
+			t.Cleanup(func() {
+				client.InstallProtocol(scheme, nil)
+			})
+
+			laptop := filepath.Join(dir, "laptop")
+			clone(t, scheme+"://"+remote, laptop)
+			write(t, laptop, "root", lines(line("A", idA), line("B", idB)))
+
+			storage := newGitStorage(laptop)
+			_, err = storage.Sync("add B")
+			if tc.err && (err == nil || !rejected(err)) {
+				t.Errorf("unexpected error; want rejected push, got %q", err)
+			}
+
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error; got %q", err)
+			}
+
+			if pushes != tc.pushes {
+				t.Errorf("unexpected push attempts; want %d, got %d", tc.pushes, pushes)
+			}
+
+			if tc.err {
+				return
+			}
+
+			unpushed, err := storage.Unpushed()
+			if err != nil || unpushed != 0 {
+				t.Errorf("unexpected commits left to push; got %d, %q", unpushed, err)
+			}
+
+			assertContent(t, laptop, "go", lines(line("C1", idC)))
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repo := seed(t, filepath.Join(dir, "remote.git"), dir)
+	commit(t, repo, dir, "root", lines(line("A", idA), line("B", idB)))
+	commit(t, repo, dir, "go", lines(line("C", idC), line("D", idD)))
+	commit(t, repo, dir, "old", lines(line("D", idD)))
+
+	write(t, dir, "root", lines(line("A2", idA), line("E", idE)))
+	write(t, dir, "go", lines(line("D", idD), line("C", idC)))
+	write(t, dir, "new", lines(line("B", idB)))
+	err := os.Remove(filepath.Join(dir, "old"))
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	got, err := newGitStorage(dir).Diff()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	type change struct {
+		Label string
+		Kind  ChangeKind
+		Key   string
+	}
+
+	want := []change{
+		{Label: "go", Kind: Reordered},
+		{Label: "new", Kind: Added, Key: idB},
+		{Label: "old", Kind: Removed, Key: idD},
+		{Label: "root", Kind: Modified, Key: idA},
+		{Label: "root", Kind: Added, Key: idE},
+		{Label: "root", Kind: Removed, Key: idB},
+	}
+
+	var res []change
+	for _, c := range got {
+		res = append(res, change{Label: c.Label, Kind: c.Kind, Key: c.Key})
+	}
+
+	if diff := cmp.Diff(res, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestDiffTouched(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repo := seed(t, filepath.Join(dir, "remote.git"), dir)
+	commit(t, repo, dir, "root", lines(line("A", idA)))
+
+	write(t, dir, "root", append(lines(line("A", idA)), '\n'))
+	write(t, dir, ".gitignore", []byte("*.tmp\n"))
+
+	got, err := newGitStorage(dir).Diff()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	want := []Change{
+		{Label: ".gitignore", Kind: Touched},
+		{Label: "root", Kind: Touched},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("output not matching; (-got, +want):\n %s", diff)
+	}
+}
+
+func TestFetchMerge(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	origin := seed(t, remote, filepath.Join(dir, "seed"))
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA)))
+	push(t, origin)
+
+	laptop := filepath.Join(dir, "laptop")
+	clone(t, remote, laptop)
+	commit(t, origin, filepath.Join(dir, "seed"), "root", lines(line("A", idA), line("B", idB)))
+	push(t, origin)
+
+	storage := newGitStorage(laptop)
+	err = storage.Fetch()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	// Fetching alone leaves the bookmarks untouched.
+	assertContent(t, laptop, "root", lines(line("A", idA)))
+
+	err = storage.Merge()
+	if err != nil {
+		t.Fatalf("unexpected error; got %q", err)
+	}
+
+	assertContent(t, laptop, "root", lines(line("A", idA), line("B", idB)))
+}